@@ -0,0 +1,139 @@
+package componentlabel
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The neighbors of a cell that can be in the same component as it
+type Connectivity int
+
+const (
+	// Cells connect to the cells sharing an edge
+	CONNECTIVITY_FOUR Connectivity = 4
+
+	// Cells connect to the cells sharing an edge or a corner
+	CONNECTIVITY_EIGHT Connectivity = 8
+)
+
+var (
+	ErrorUnknownConnectivity error = errors.New("connectivity must be 4 or 8")
+	ErrorInvalidGridSize     error = errors.New("grid dimensions must be non-negative")
+	ErrorRaggedGrid          error = errors.New("grid rows have different lengths")
+)
+
+// Reports if two neighboring cells, given by their x and y coordinates, belong in the same component.
+// This must be symmetric.
+type SameRegionFunc func(x1, y1, x2, y2 int) bool
+
+// The component of every cell of a grid. Components are numbered from 0 in the order their first cell
+// appears, reading the grid row by row.
+type Labelling struct {
+	Width         int
+	Height        int
+	NumComponents int
+
+	// The component of each cell, indexed by y * Width + x
+	Labels []int
+}
+
+func (labelling *Labelling) Label(x, y int) int {
+	return labelling.Labels[y*labelling.Width+x]
+}
+
+// Get the number of cells in each component
+func (labelling *Labelling) ComponentSizes() []int {
+	sizes := make([]int, labelling.NumComponents)
+	for _, label := range labelling.Labels {
+		sizes[label] += 1
+	}
+	return sizes
+}
+
+// Label the connected components of a grid with the classic two pass algorithm.
+//
+// The first pass reads the grid in raster order, giving each cell the provisional label of a neighbor already
+// read (to the left, above, and for eight connectivity the two upper diagonals) in the same region, or a new
+// label if there are none. Neighbors found to share a component with different labels have their labels merged
+// in a union-find forest. The second pass replaces each provisional label with its final component number.
+func LabelComponents(width, height int, connectivity Connectivity, sameRegion SameRegionFunc) (*Labelling, error) {
+	if width < 0 || height < 0 {
+		return nil, fmt.Errorf("%w: %dx%d", ErrorInvalidGridSize, width, height)
+	}
+
+	// Offsets of the neighbors read before each cell
+	var previousNeighborOffsets [][2]int
+	switch connectivity {
+	case CONNECTIVITY_FOUR:
+		previousNeighborOffsets = [][2]int{{-1, 0}, {0, -1}}
+	case CONNECTIVITY_EIGHT:
+		previousNeighborOffsets = [][2]int{{-1, 0}, {-1, -1}, {0, -1}, {1, -1}}
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrorUnknownConnectivity, connectivity)
+	}
+
+	provisionalLabels := make([]int, width*height)
+	forest := newUnionFind(width * height / 4)
+	for y := range height {
+		for x := range width {
+			cellLabel := -1
+			for _, offset := range previousNeighborOffsets {
+				neighborX, neighborY := x+offset[0], y+offset[1]
+				if neighborX < 0 || neighborX >= width || neighborY < 0 || !sameRegion(x, y, neighborX, neighborY) {
+					continue
+				}
+				neighborLabel := provisionalLabels[neighborY*width+neighborX]
+				if cellLabel == -1 {
+					cellLabel = neighborLabel
+				} else if neighborLabel != cellLabel {
+					cellLabel = forest.union(cellLabel, neighborLabel)
+				}
+			}
+			if cellLabel == -1 {
+				cellLabel = forest.makeSet()
+			}
+			provisionalLabels[y*width+x] = cellLabel
+		}
+	}
+
+	// Number each set by first appearance, reusing the provisional label slice for the final labels
+	componentOfRoot := make([]int, len(forest.parent))
+	for root := range componentOfRoot {
+		componentOfRoot[root] = -1
+	}
+	numComponents := 0
+	for cellIndex, provisionalLabel := range provisionalLabels {
+		root := forest.find(provisionalLabel)
+		if componentOfRoot[root] == -1 {
+			componentOfRoot[root] = numComponents
+			numComponents += 1
+		}
+		provisionalLabels[cellIndex] = componentOfRoot[root]
+	}
+
+	return &Labelling{
+		Width:         width,
+		Height:        height,
+		NumComponents: numComponents,
+		Labels:        provisionalLabels,
+	}, nil
+}
+
+// Label the connected components of a grid, where neighboring cells are in the same region if they are equal.
+// The grid is indexed by y then x.
+func LabelGrid[T comparable](grid [][]T, connectivity Connectivity) (*Labelling, error) {
+	height := len(grid)
+	width := 0
+	if height > 0 {
+		width = len(grid[0])
+	}
+	for _, row := range grid {
+		if len(row) != width {
+			return nil, ErrorRaggedGrid
+		}
+	}
+
+	return LabelComponents(width, height, connectivity, func(x1, y1, x2, y2 int) bool {
+		return grid[y1][x1] == grid[y2][x2]
+	})
+}
@@ -0,0 +1,84 @@
+package componentlabel_test
+
+import (
+	"hmcalister/AdventOfCode/componentlabel"
+	"hmcalister/AdventOfCode/garden"
+	"io"
+	"log/slog"
+	"math/rand"
+	"testing"
+)
+
+const (
+	// Side length of the random gardens benchmarked on
+	BENCHMARK_GARDEN_SIZE int = 1000
+
+	// Number of distinct plants in the random gardens
+	BENCHMARK_NUM_PLANTS int = 4
+
+	// Seed of the random gardens, so runs are comparable
+	BENCHMARK_SEED int64 = 12
+)
+
+// Generate a square garden of random plants
+func randomGardenData(size int, numPlants int) [][]rune {
+	rng := rand.New(rand.NewSource(BENCHMARK_SEED))
+	gardenData := make([][]rune, size)
+	for y := range gardenData {
+		gardenData[y] = make([]rune, size)
+		for x := range gardenData[y] {
+			gardenData[y][x] = rune('A' + rng.Intn(numPlants))
+		}
+	}
+	return gardenData
+}
+
+// Drop all but warnings and errors from the log for the rest of the test, so the garden does not log every plot
+func quietLogs(tb testing.TB) {
+	previousLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelWarn})))
+	tb.Cleanup(func() {
+		slog.SetDefault(previousLogger)
+	})
+}
+
+func TestLabelGridMatchesFloodFill(t *testing.T) {
+	quietLogs(t)
+	for _, numPlants := range []int{1, 2, 4, 26} {
+		gardenData := randomGardenData(100, numPlants)
+		labelling, err := componentlabel.LabelGrid(gardenData, componentlabel.CONNECTIVITY_FOUR)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if floodFillRegions := garden.NewGarden(gardenData).NumRegions(); labelling.NumComponents != floodFillRegions {
+			t.Errorf("%d plants: %d regions by union-find, %d by flood fill", numPlants, labelling.NumComponents, floodFillRegions)
+		}
+	}
+}
+
+func BenchmarkFloodFill(b *testing.B) {
+	quietLogs(b)
+	gardenData := randomGardenData(BENCHMARK_GARDEN_SIZE, BENCHMARK_NUM_PLANTS)
+	b.ResetTimer()
+	for range b.N {
+		garden.NewGarden(gardenData)
+	}
+}
+
+func benchmarkLabelGrid(b *testing.B, connectivity componentlabel.Connectivity) {
+	gardenData := randomGardenData(BENCHMARK_GARDEN_SIZE, BENCHMARK_NUM_PLANTS)
+	b.ResetTimer()
+	for range b.N {
+		if _, err := componentlabel.LabelGrid(gardenData, connectivity); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLabelGridFourConnectivity(b *testing.B) {
+	benchmarkLabelGrid(b, componentlabel.CONNECTIVITY_FOUR)
+}
+
+func BenchmarkLabelGridEightConnectivity(b *testing.B) {
+	benchmarkLabelGrid(b, componentlabel.CONNECTIVITY_EIGHT)
+}
@@ -0,0 +1,48 @@
+package componentlabel
+
+// A disjoint set forest over the integers 0 to n-1, with path compression and union by size
+type unionFind struct {
+	parent []int
+	size   []int
+}
+
+func newUnionFind(capacity int) *unionFind {
+	return &unionFind{
+		parent: make([]int, 0, capacity),
+		size:   make([]int, 0, capacity),
+	}
+}
+
+// Add a new singleton set, returning its element
+func (forest *unionFind) makeSet() int {
+	element := len(forest.parent)
+	forest.parent = append(forest.parent, element)
+	forest.size = append(forest.size, 1)
+	return element
+}
+
+// Get the root of the set containing the element, pointing every element on the way directly at the root
+func (forest *unionFind) find(element int) int {
+	root := element
+	for forest.parent[root] != root {
+		root = forest.parent[root]
+	}
+	for forest.parent[element] != root {
+		element, forest.parent[element] = forest.parent[element], root
+	}
+	return root
+}
+
+// Merge the sets containing the two elements, returning the root of the merged set
+func (forest *unionFind) union(a, b int) int {
+	rootA, rootB := forest.find(a), forest.find(b)
+	if rootA == rootB {
+		return rootA
+	}
+	if forest.size[rootA] < forest.size[rootB] {
+		rootA, rootB = rootB, rootA
+	}
+	forest.parent[rootB] = rootA
+	forest.size[rootA] += forest.size[rootB]
+	return rootA
+}
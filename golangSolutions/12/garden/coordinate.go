@@ -1,8 +1,8 @@
 package garden
 
 type Coordinate struct {
-	X int
-	Y int
+	X int `json:"x"`
+	Y int `json:"y"`
 }
 
 func (c Coordinate) GetOrthogonalNeighbors() []Coordinate {
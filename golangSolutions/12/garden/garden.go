@@ -1,6 +1,7 @@
 package garden
 
 import (
+	"context"
 	"log/slog"
 
 	hashset "github.com/hmcalister/Go-DSA/set/HashSet"
@@ -13,15 +14,22 @@ type Garden struct {
 	height             int
 	plots              []*plot
 	coordinatesInPlots *hashset.HashSet[Coordinate]
+
+	// The index of the plot containing each coordinate, indexed by y then x
+	plotIndices [][]int
 }
 
 func NewGarden(gardenData [][]rune) *Garden {
 	garden := &Garden{
 		gardenData:         gardenData,
-		width:              len(gardenData),
-		height:             len(gardenData[0]),
+		width:              len(gardenData[0]),
+		height:             len(gardenData),
 		plots:              make([]*plot, 0),
 		coordinatesInPlots: hashset.New[Coordinate](),
+		plotIndices:        make([][]int, len(gardenData)),
+	}
+	for y := range garden.plotIndices {
+		garden.plotIndices[y] = make([]int, garden.width)
 	}
 
 	garden.initialize()
@@ -46,6 +54,7 @@ func (garden *Garden) initialize() {
 
 func (garden *Garden) addNewPlot(initialCoordinate Coordinate) {
 	p := newPlot()
+	plotIndex := len(garden.plots)
 	garden.plots = append(garden.plots, p)
 	initialRune := garden.gardenData[initialCoordinate.Y][initialCoordinate.X]
 
@@ -65,12 +74,16 @@ func (garden *Garden) addNewPlot(initialCoordinate Coordinate) {
 		}
 		// slog.Debug("found additional plot coordinate", "initial coordinate", initialCoordinate, "current coordinate", currentCoordinate)
 		garden.coordinatesInPlots.Add(currentCoordinate)
+		garden.plotIndices[currentCoordinate.Y][currentCoordinate.X] = plotIndex
 		p.Add(currentCoordinate)
 		for _, neighbor := range currentCoordinate.GetOrthogonalNeighbors() {
 			fillCoordinateStack.Add(neighbor)
 		}
 	}
-	slog.Info("new plot initialized", "plot rune", initialRune, "plot area", p.coordinates.Size(), "plot perimeter", p.perimeter, "plot edges", p.countEdges())
+	// Counting the edges of the plot is slow, so is only done if the plot will be logged
+	if slog.Default().Enabled(context.Background(), slog.LevelInfo) {
+		slog.Info("new plot initialized", "plot rune", initialRune, "plot area", p.coordinates.Size(), "plot perimeter", p.perimeter, "plot edges", p.countEdges())
+	}
 }
 
 func (garden *Garden) FencingPrice() int {
@@ -88,3 +101,7 @@ func (garden *Garden) DiscountFencingPrice() int {
 	}
 	return totalFencingPrice
 }
+
+func (garden *Garden) NumRegions() int {
+	return len(garden.plots)
+}
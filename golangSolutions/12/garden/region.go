@@ -0,0 +1,237 @@
+package garden
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	hashset "github.com/hmcalister/Go-DSA/set/HashSet"
+)
+
+const (
+	// The region id of nothing, used when a region is not enclosed by any other
+	NO_REGION int = -1
+)
+
+var (
+	// Header of the CSV written by WriteRegionsCSV
+	regionCSVHeader = []string{"id", "plant", "area", "perimeter", "sides", "min_x", "min_y", "max_x", "max_y", "holes", "enclosed_by", "enclosed", "adjacent"}
+)
+
+type BoundingBox struct {
+	Min Coordinate `json:"min"`
+	Max Coordinate `json:"max"`
+}
+
+// Everything known about one region of the garden. Regions are identified by their index in Garden.Regions.
+type Region struct {
+	ID          int         `json:"id"`
+	Plant       string      `json:"plant"`
+	Area        int         `json:"area"`
+	Perimeter   int         `json:"perimeter"`
+	Sides       int         `json:"sides"`
+	BoundingBox BoundingBox `json:"boundingBox"`
+
+	// Number of separate pockets of other plants completely surrounded by this region. Pockets meeting only at a
+	// corner are separate, just as they have separate fences.
+	Holes int `json:"holes"`
+
+	// The innermost region this region sits in a hole of, or NO_REGION
+	EnclosedBy int `json:"enclosedBy"`
+
+	// Regions for which this is the innermost enclosing region, i.e. the children of this region in the nesting tree
+	Enclosed []int `json:"enclosed"`
+
+	// Regions sharing a fence with this region
+	Adjacent []int `json:"adjacent"`
+}
+
+// Get the regions sharing a fence with each region. The lists include outsideIndex for regions on the edge of
+// the garden, and there is a list for the outside too.
+func (garden *Garden) regionAdjacency(outsideIndex int) [][]int {
+	adjacent := make([][]int, len(garden.plots)+1)
+
+	for y := range garden.height {
+		for x := range garden.width {
+			c := Coordinate{x, y}
+			regionIndex := garden.plotIndices[y][x]
+			for _, neighbor := range c.GetOrthogonalNeighbors() {
+				neighborIndex := outsideIndex
+				if garden.isInBounds(neighbor) {
+					neighborIndex = garden.plotIndices[neighbor.Y][neighbor.X]
+				}
+				if neighborIndex != regionIndex {
+					adjacent[regionIndex] = append(adjacent[regionIndex], neighborIndex)
+					adjacent[neighborIndex] = append(adjacent[neighborIndex], regionIndex)
+				}
+			}
+		}
+	}
+
+	// Each pair of regions is seen twice for every pair of cells along their shared fence
+	for regionIndex := range adjacent {
+		slices.Sort(adjacent[regionIndex])
+		adjacent[regionIndex] = slices.Clip(slices.Compact(adjacent[regionIndex]))
+	}
+	return adjacent
+}
+
+// Count the holes of each region, and find the innermost region enclosing each region.
+//
+// Consider the graph of regions where regions sharing a fence are connected, with an extra node for everything
+// outside the garden. The holes of a region are the pieces this graph falls into when the region is
+// removed, other than the piece containing the outside. These are found for every region at once by the
+// articulation point algorithm, running a depth first search from the outside: a region has a hole for each
+// child c in the search tree with low(c) >= discovery(region), and everything in the subtree of that child sits
+// in the hole.
+func enclosures(adjacent [][]int, outsideIndex int) ([]int, []int) {
+	numNodes := len(adjacent)
+	discovery := make([]int, numNodes)
+	low := make([]int, numNodes)
+	parent := make([]int, numNodes)
+	for node := range discovery {
+		discovery[node] = -1
+		parent[node] = NO_REGION
+	}
+
+	// The search is iterative, as a garden may have as many regions as cells
+	type searchFrame struct {
+		node         int
+		neighborNext int
+	}
+	preorder := make([]int, 0, numNodes)
+	discovery[outsideIndex] = 0
+	low[outsideIndex] = 0
+	preorder = append(preorder, outsideIndex)
+	searchStack := []searchFrame{{outsideIndex, 0}}
+	for len(searchStack) > 0 {
+		frame := &searchStack[len(searchStack)-1]
+		if frame.neighborNext < len(adjacent[frame.node]) {
+			neighbor := adjacent[frame.node][frame.neighborNext]
+			frame.neighborNext += 1
+			if discovery[neighbor] == -1 {
+				discovery[neighbor] = len(preorder)
+				low[neighbor] = discovery[neighbor]
+				parent[neighbor] = frame.node
+				preorder = append(preorder, neighbor)
+				searchStack = append(searchStack, searchFrame{neighbor, 0})
+			} else if neighbor != parent[frame.node] {
+				low[frame.node] = min(low[frame.node], discovery[neighbor])
+			}
+			continue
+		}
+
+		searchStack = searchStack[:len(searchStack)-1]
+		if parentNode := parent[frame.node]; parentNode != NO_REGION {
+			low[parentNode] = min(low[parentNode], low[frame.node])
+		}
+	}
+
+	holes := make([]int, numNodes)
+	enclosedBy := make([]int, numNodes)
+	for _, node := range preorder {
+		enclosedBy[node] = NO_REGION
+		parentNode := parent[node]
+		if parentNode == NO_REGION {
+			continue
+		}
+		// The parent cuts this subtree off from the outside, or else this region shares the parent's enclosure
+		if parentNode != outsideIndex && low[node] >= discovery[parentNode] {
+			holes[parentNode] += 1
+			enclosedBy[node] = parentNode
+		} else {
+			enclosedBy[node] = enclosedBy[parentNode]
+		}
+	}
+	return holes[:outsideIndex], enclosedBy[:outsideIndex]
+}
+
+// Get every region of the garden, with its measurements, holes, place in the nesting tree, and neighbors
+func (garden *Garden) Regions() []Region {
+	outsideIndex := len(garden.plots)
+	adjacent := garden.regionAdjacency(outsideIndex)
+	holes, enclosedBy := enclosures(adjacent, outsideIndex)
+
+	regions := make([]Region, len(garden.plots))
+	for regionIndex, p := range garden.plots {
+		boundingBox := BoundingBox{
+			Min: Coordinate{garden.width, garden.height},
+			Max: Coordinate{-1, -1},
+		}
+		var plant rune
+		hashset.Apply(p.coordinates, func(c Coordinate) {
+			plant = garden.gardenData[c.Y][c.X]
+			boundingBox.Min = Coordinate{min(boundingBox.Min.X, c.X), min(boundingBox.Min.Y, c.Y)}
+			boundingBox.Max = Coordinate{max(boundingBox.Max.X, c.X), max(boundingBox.Max.Y, c.Y)}
+		})
+
+		regions[regionIndex] = Region{
+			ID:          regionIndex,
+			Plant:       string(plant),
+			Area:        p.coordinates.Size(),
+			Perimeter:   p.perimeter,
+			Sides:       p.countEdges(),
+			BoundingBox: boundingBox,
+			Holes:       holes[regionIndex],
+			EnclosedBy:  enclosedBy[regionIndex],
+			Enclosed:    make([]int, 0),
+			Adjacent:    slices.DeleteFunc(adjacent[regionIndex], func(neighborIndex int) bool { return neighborIndex == outsideIndex }),
+		}
+	}
+	for regionIndex, parentIndex := range enclosedBy {
+		if parentIndex != NO_REGION {
+			regions[parentIndex].Enclosed = append(regions[parentIndex].Enclosed, regionIndex)
+		}
+	}
+	return regions
+}
+
+// ------------------------------------------------------------------------------------------------------------------------
+
+func joinRegionIDs(ids []int) string {
+	idStrs := make([]string, len(ids))
+	for idIndex, id := range ids {
+		idStrs[idIndex] = strconv.Itoa(id)
+	}
+	return strings.Join(idStrs, ";")
+}
+
+// Write the regions as CSV with a header row. Lists of region ids are separated by semicolons.
+func WriteRegionsCSV(writer io.Writer, regions []Region) error {
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write(regionCSVHeader); err != nil {
+		return err
+	}
+	for _, region := range regions {
+		record := []string{
+			strconv.Itoa(region.ID),
+			region.Plant,
+			strconv.Itoa(region.Area),
+			strconv.Itoa(region.Perimeter),
+			strconv.Itoa(region.Sides),
+			strconv.Itoa(region.BoundingBox.Min.X),
+			strconv.Itoa(region.BoundingBox.Min.Y),
+			strconv.Itoa(region.BoundingBox.Max.X),
+			strconv.Itoa(region.BoundingBox.Max.Y),
+			strconv.Itoa(region.Holes),
+			strconv.Itoa(region.EnclosedBy),
+			joinRegionIDs(region.Enclosed),
+			joinRegionIDs(region.Adjacent),
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// Write the regions as an indented JSON array
+func WriteRegionsJSON(writer io.Writer, regions []Region) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(regions)
+}
@@ -14,11 +14,18 @@ const (
 	CPU_PROFILE_FILEPATH string = "profile"
 )
 
+var (
+	regionsFilePath string
+	regionsFormat   string
+)
+
 func main() {
 	debugFlag := flag.Bool("debug", false, "Debug Flag")
 	inputFilePath := flag.String("inputFile", "puzzleInput", "Path to input file.")
 	selectedPart := flag.Int("part", 0, "Part to execute. Must be 1 or 2.")
 	profile := flag.Bool("profile", false, "Flag to profile program")
+	flag.StringVar(&regionsFilePath, "regionsFile", "", "File to export every region of the garden to. No export is made if empty.")
+	flag.StringVar(&regionsFormat, "regionsFormat", REGIONS_FORMAT_CSV, "Format of the exported regions. One of csv or json.")
 	flag.Parse()
 	if *profile {
 		f, err := os.Create(CPU_PROFILE_FILEPATH)
@@ -72,6 +79,11 @@ func Part01(fileScanner *bufio.Scanner) (int, error) {
 		gardenData = append(gardenData, []rune(fileScanner.Text()))
 	}
 	garden := garden.NewGarden(gardenData)
+	if regionsFilePath != "" {
+		if err := exportRegions(garden); err != nil {
+			return 0, err
+		}
+	}
 
 	return garden.FencingPrice(), nil
 }
@@ -82,6 +94,11 @@ func Part02(fileScanner *bufio.Scanner) (int, error) {
 		gardenData = append(gardenData, []rune(fileScanner.Text()))
 	}
 	garden := garden.NewGarden(gardenData)
+	if regionsFilePath != "" {
+		if err := exportRegions(garden); err != nil {
+			return 0, err
+		}
+	}
 
 	return garden.DiscountFencingPrice(), nil
 }
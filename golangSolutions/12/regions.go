@@ -0,0 +1,45 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"hmcalister/AdventOfCode/garden"
+	"io"
+	"log/slog"
+	"os"
+)
+
+const (
+	REGIONS_FORMAT_CSV  string = "csv"
+	REGIONS_FORMAT_JSON string = "json"
+)
+
+var (
+	ErrorUnknownRegionsFormat error = errors.New("unknown regions format")
+)
+
+// Write every region of the garden to the selected file in the selected format
+func exportRegions(gardenMap *garden.Garden) error {
+	var writeRegions func(io.Writer, []garden.Region) error
+	switch regionsFormat {
+	case REGIONS_FORMAT_CSV:
+		writeRegions = garden.WriteRegionsCSV
+	case REGIONS_FORMAT_JSON:
+		writeRegions = garden.WriteRegionsJSON
+	default:
+		return fmt.Errorf("%w: %q", ErrorUnknownRegionsFormat, regionsFormat)
+	}
+
+	regions := gardenMap.Regions()
+	regionsFile, err := os.Create(regionsFilePath)
+	if err != nil {
+		return err
+	}
+	defer regionsFile.Close()
+	if err := writeRegions(regionsFile, regions); err != nil {
+		return err
+	}
+
+	slog.Info("exported regions", "file", regionsFilePath, "format", regionsFormat, "regions", len(regions))
+	return nil
+}
@@ -32,6 +32,10 @@ func floatIntConversion(x float64) (int, error) {
 type ClawMachine struct {
 	coordinateIncrementMatrix *mat.Dense
 	targetCoordinateVector    *mat.VecDense
+
+	// Exact copies of the button increments (indexed by button then axis) and prize, for the integer solver
+	buttonIncrements [2][2]int64
+	prizeCoordinates [2]int64
 }
 
 func NewClawMachine(buttonAX, buttonAY, buttonBX, buttonBY, prizeX, prizeY float64) *ClawMachine {
@@ -42,16 +46,27 @@ func NewClawMachine(buttonAX, buttonAY, buttonBX, buttonBY, prizeX, prizeY float
 		prizeX, prizeY,
 	})
 	return &ClawMachine{
-		coordinateIncrementMatrix,
-		targetCoordinateVector,
+		coordinateIncrementMatrix: coordinateIncrementMatrix,
+		targetCoordinateVector:    targetCoordinateVector,
+		buttonIncrements: [2][2]int64{
+			{int64(buttonAX), int64(buttonAY)},
+			{int64(buttonBX), int64(buttonBY)},
+		},
+		prizeCoordinates: [2]int64{int64(prizeX), int64(prizeY)},
 	}
 }
 
 func (machine *ClawMachine) FixUnitConversion() {
 	machine.targetCoordinateVector.AddVec(machine.targetCoordinateVector, unitConversionFix)
+	for axis := range machine.prizeCoordinates {
+		machine.prizeCoordinates[axis] += int64(unitConversionFix.AtVec(axis))
+	}
 }
 
-func (machine *ClawMachine) ComputeLowestTokenCost() (int, error) {
+// Compute the lowest token cost by solving the system in floating point, then checking the solution is close to integral.
+//
+// Prefer ComputeLowestTokenCost, which is exact. This may misjudge integrality once the prize coordinates are large.
+func (machine *ClawMachine) ComputeLowestTokenCostFloat() (int, error) {
 	var buttonPushes mat.VecDense
 	err := buttonPushes.SolveVec(machine.coordinateIncrementMatrix, machine.targetCoordinateVector)
 	if err != nil {
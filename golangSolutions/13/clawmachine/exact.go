@@ -0,0 +1,263 @@
+package clawmachine
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/big"
+)
+
+var (
+	ErrorNonIntegralPresses error = errors.New("prize needs a fractional number of button presses")
+	ErrorNegativePresses    error = errors.New("prize needs a negative number of button presses")
+	ErrorPrizeOffButtonLine error = errors.New("buttons move along a single line that misses the prize")
+	ErrorIntegerOverflow    error = errors.New("button presses or cost do not fit in an int64")
+)
+
+// Get the token cost of each button as integers
+func buttonTokenCostsInt() [2]int64 {
+	return [2]int64{int64(buttonTokenCosts.AtVec(0)), int64(buttonTokenCosts.AtVec(1))}
+}
+
+// Multiply two int64s, reporting false if the result overflows
+func mulInt64(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	product := a * b
+	if product/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+	return product, true
+}
+
+// Subtract two int64s, reporting false if the result overflows
+func subInt64(a, b int64) (int64, bool) {
+	difference := a - b
+	if (b > 0 && difference > a) || (b < 0 && difference < a) {
+		return 0, false
+	}
+	return difference, true
+}
+
+// Compute ad - bc, reporting false if any step overflows
+func crossInt64(a, b, c, d int64) (int64, bool) {
+	ad, adOk := mulInt64(a, d)
+	bc, bcOk := mulInt64(b, c)
+	if !adOk || !bcOk {
+		return 0, false
+	}
+	return subInt64(ad, bc)
+}
+
+// Compute the number of presses of each button to reach the prize exactly, using only integer arithmetic.
+//
+// When the buttons are independent Cramer's rule gives the unique solution, computed in int64 if possible and
+// with math/big otherwise. When the buttons are collinear (the determinant is zero) there may be many solutions,
+// and the cheapest is found by extendedEuclidPresses.
+func (machine *ClawMachine) solveButtonPresses() ([2]int64, error) {
+	ax, ay := machine.buttonIncrements[0][0], machine.buttonIncrements[0][1]
+	bx, by := machine.buttonIncrements[1][0], machine.buttonIncrements[1][1]
+	px, py := machine.prizeCoordinates[0], machine.prizeCoordinates[1]
+
+	determinant, determinantOk := crossInt64(ax, bx, ay, by)
+	if determinantOk && determinant == 0 {
+		return machine.extendedEuclidPresses()
+	}
+
+	numeratorA, numeratorAOk := crossInt64(px, bx, py, by)
+	numeratorB, numeratorBOk := crossInt64(ax, px, ay, py)
+	if !determinantOk || !numeratorAOk || !numeratorBOk {
+		slog.Debug("cramer's rule overflowed int64, using math/big", "machine", machine)
+		return machine.cramerPressesBig()
+	}
+
+	if numeratorA%determinant != 0 || numeratorB%determinant != 0 {
+		return [2]int64{}, ErrorNonIntegralPresses
+	}
+	presses := [2]int64{numeratorA / determinant, numeratorB / determinant}
+	if presses[0] < 0 || presses[1] < 0 {
+		return [2]int64{}, fmt.Errorf("%w: %v", ErrorNegativePresses, presses)
+	}
+	return presses, nil
+}
+
+// Solve with Cramer's rule in arbitrary precision, for when the int64 computation overflows
+func (machine *ClawMachine) cramerPressesBig() ([2]int64, error) {
+	ax, ay := big.NewInt(machine.buttonIncrements[0][0]), big.NewInt(machine.buttonIncrements[0][1])
+	bx, by := big.NewInt(machine.buttonIncrements[1][0]), big.NewInt(machine.buttonIncrements[1][1])
+	px, py := big.NewInt(machine.prizeCoordinates[0]), big.NewInt(machine.prizeCoordinates[1])
+	cross := func(a, b, c, d *big.Int) *big.Int {
+		ad := new(big.Int).Mul(a, d)
+		return ad.Sub(ad, new(big.Int).Mul(b, c))
+	}
+
+	determinant := cross(ax, bx, ay, by)
+	if determinant.Sign() == 0 {
+		return machine.extendedEuclidPresses()
+	}
+	numerators := []*big.Int{cross(px, bx, py, by), cross(ax, px, ay, py)}
+
+	var presses [2]int64
+	for buttonIndex, numerator := range numerators {
+		quotient, remainder := new(big.Int).QuoRem(numerator, determinant, new(big.Int))
+		if remainder.Sign() != 0 {
+			return [2]int64{}, ErrorNonIntegralPresses
+		}
+		if quotient.Sign() < 0 {
+			return [2]int64{}, fmt.Errorf("%w: button %d pressed %v times", ErrorNegativePresses, buttonIndex, quotient)
+		}
+		if !quotient.IsInt64() {
+			return [2]int64{}, ErrorIntegerOverflow
+		}
+		presses[buttonIndex] = quotient.Int64()
+	}
+	return presses, nil
+}
+
+// Get the floor of n / d for any non-zero d
+func floorDivBig(n, d *big.Int) *big.Int {
+	n, d = new(big.Int).Set(n), new(big.Int).Set(d)
+	if d.Sign() < 0 {
+		n.Neg(n)
+		d.Neg(d)
+	}
+	// Euclidean division rounds down for positive divisors
+	return n.Div(n, d)
+}
+
+func ceilDivBig(n, d *big.Int) *big.Int {
+	negatedFloor := floorDivBig(new(big.Int).Neg(n), d)
+	return negatedFloor.Neg(negatedFloor)
+}
+
+// Find the cheapest presses when the buttons are collinear.
+//
+// The prize must lie on the line of the buttons, reducing the problem to the single Diophantine equation
+// a*u + b*v = w along an axis the line is not perpendicular to. The extended Euclidean algorithm gives one
+// solution (a0, b0), and every solution is a = a0 + k*v/g, b = b0 - k*u/g for g = gcd(u, v). Requiring both
+// presses be non-negative bounds k to an interval, and as the cost is linear in k the cheapest solution is at
+// one end of that interval.
+func (machine *ClawMachine) extendedEuclidPresses() ([2]int64, error) {
+	buttonA, buttonB, prize := machine.buttonIncrements[0], machine.buttonIncrements[1], machine.prizeCoordinates
+
+	// Any non-zero button gives the direction of the line, and the prize must be parallel to it
+	direction := buttonA
+	if direction == [2]int64{} {
+		direction = buttonB
+	}
+	if direction == [2]int64{} {
+		if prize == [2]int64{} {
+			return [2]int64{0, 0}, nil
+		}
+		return [2]int64{}, fmt.Errorf("%w: neither button moves the claw", ErrorPrizeOffButtonLine)
+	}
+	offLine := new(big.Int).Sub(
+		new(big.Int).Mul(big.NewInt(direction[0]), big.NewInt(prize[1])),
+		new(big.Int).Mul(big.NewInt(direction[1]), big.NewInt(prize[0])),
+	)
+	if offLine.Sign() != 0 {
+		return [2]int64{}, ErrorPrizeOffButtonLine
+	}
+
+	axis := 0
+	if direction[0] == 0 {
+		axis = 1
+	}
+	u, v, w := big.NewInt(buttonA[axis]), big.NewInt(buttonB[axis]), big.NewInt(prize[axis])
+
+	x, y := new(big.Int), new(big.Int)
+	g := new(big.Int).GCD(x, y, u, v)
+	if new(big.Int).Rem(w, g).Sign() != 0 {
+		return [2]int64{}, ErrorNonIntegralPresses
+	}
+	scale := new(big.Int).Quo(w, g)
+	a0, b0 := x.Mul(x, scale), y.Mul(y, scale)
+	p, q := new(big.Int).Quo(v, g), new(big.Int).Quo(u, g)
+
+	// Collect the bounds on k from a0 + p*k >= 0 and b0 - q*k >= 0
+	var lowerBound, upperBound *big.Int
+	tightenLower := func(bound *big.Int) {
+		if lowerBound == nil || bound.Cmp(lowerBound) > 0 {
+			lowerBound = bound
+		}
+	}
+	tightenUpper := func(bound *big.Int) {
+		if upperBound == nil || bound.Cmp(upperBound) < 0 {
+			upperBound = bound
+		}
+	}
+	switch p.Sign() {
+	case 1:
+		tightenLower(ceilDivBig(new(big.Int).Neg(a0), p))
+	case -1:
+		tightenUpper(floorDivBig(new(big.Int).Neg(a0), p))
+	default:
+		if a0.Sign() < 0 {
+			return [2]int64{}, fmt.Errorf("%w: button A pressed %v times", ErrorNegativePresses, a0)
+		}
+	}
+	switch q.Sign() {
+	case 1:
+		tightenUpper(floorDivBig(b0, q))
+	case -1:
+		tightenLower(ceilDivBig(b0, q))
+	default:
+		if b0.Sign() < 0 {
+			return [2]int64{}, fmt.Errorf("%w: button B pressed %v times", ErrorNegativePresses, b0)
+		}
+	}
+	if lowerBound != nil && upperBound != nil && lowerBound.Cmp(upperBound) > 0 {
+		return [2]int64{}, ErrorNegativePresses
+	}
+
+	// The cost changes by costA*p - costB*q for each increment of k, so walk k towards the cheaper end
+	costs := buttonTokenCostsInt()
+	slope := new(big.Int).Sub(
+		new(big.Int).Mul(big.NewInt(costs[0]), p),
+		new(big.Int).Mul(big.NewInt(costs[1]), q),
+	)
+	k := lowerBound
+	if slope.Sign() < 0 || k == nil {
+		k = upperBound
+	}
+	if k == nil {
+		// Only possible if neither press is bounded, which cannot happen while u and v are not both zero
+		return [2]int64{}, ErrorIncomputable
+	}
+
+	pressesA := a0.Add(a0, new(big.Int).Mul(p, k))
+	pressesB := b0.Sub(b0, new(big.Int).Mul(q, k))
+	if !pressesA.IsInt64() || !pressesB.IsInt64() {
+		return [2]int64{}, ErrorIntegerOverflow
+	}
+	return [2]int64{pressesA.Int64(), pressesB.Int64()}, nil
+}
+
+// Get the token cost of pressing each button the given number of times
+func pressesTokenCost(presses [2]int64) (int64, error) {
+	costs := buttonTokenCostsInt()
+	costA, costAOk := mulInt64(presses[0], costs[0])
+	costB, costBOk := mulInt64(presses[1], costs[1])
+	totalCost := costA + costB
+	if !costAOk || !costBOk || totalCost < costA || totalCost > math.MaxInt {
+		return 0, ErrorIntegerOverflow
+	}
+	return totalCost, nil
+}
+
+// Compute the lowest token cost to reach the prize, using exact integer arithmetic throughout
+func (machine *ClawMachine) ComputeLowestTokenCost() (int, error) {
+	presses, err := machine.solveButtonPresses()
+	if err != nil {
+		return 0, err
+	}
+
+	totalCost, err := pressesTokenCost(presses)
+	if err != nil {
+		return 0, err
+	}
+	slog.Debug("exact cost computed", "presses", presses, "total cost", totalCost)
+	return int(totalCost), nil
+}
@@ -0,0 +1,346 @@
+package clawmachine
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"slices"
+)
+
+const (
+	// Press limit of a button that may be pressed any number of times
+	NO_PRESS_LIMIT int64 = -1
+
+	// Default number of partial press combinations the bounded search may visit before giving up
+	DEFAULT_MAX_SEARCH_STATES int = 1 << 24
+)
+
+var (
+	ErrorDimensionMismatch   error = errors.New("button increments and prize have different dimensions")
+	ErrorNegativeTokenCost   error = errors.New("button token costs must be non-negative")
+	ErrorUnboundedButtons    error = errors.New("more than one button has no press limit and no axis bounding its presses")
+	ErrorSearchLimitExceeded error = errors.New("search for button presses exceeded the state limit")
+	ErrorPressLimitExceeded  error = errors.New("prize needs more presses than a button allows")
+	ErrorNoButtonCombination error = errors.New("no combination of button presses reaches the prize")
+)
+
+// A button moving the claw by some increment along each axis of the prize, for a number of tokens per press
+type Button struct {
+	Increments []int64
+	TokenCost  int64
+
+	// The most times the button may be pressed, or NO_PRESS_LIMIT
+	MaxPresses int64
+}
+
+// A claw machine with any number of buttons, and a prize in any number of dimensions
+type GeneralClawMachine struct {
+	buttons []Button
+	prize   []int64
+}
+
+func NewGeneralClawMachine(buttons []Button, prize []int64) (*GeneralClawMachine, error) {
+	for buttonIndex, button := range buttons {
+		if len(button.Increments) != len(prize) {
+			return nil, fmt.Errorf("%w: button %d has %d increments for a %d dimensional prize", ErrorDimensionMismatch, buttonIndex, len(button.Increments), len(prize))
+		}
+		if button.TokenCost < 0 {
+			return nil, fmt.Errorf("%w: button %d costs %d", ErrorNegativeTokenCost, buttonIndex, button.TokenCost)
+		}
+	}
+	return &GeneralClawMachine{
+		buttons: buttons,
+		prize:   prize,
+	}, nil
+}
+
+// Get the general form of a two button machine, with the given press limit on both buttons
+func (machine *ClawMachine) Generalize(maxPresses int64) *GeneralClawMachine {
+	costs := buttonTokenCostsInt()
+	buttons := make([]Button, len(machine.buttonIncrements))
+	for buttonIndex, increments := range machine.buttonIncrements {
+		buttons[buttonIndex] = Button{
+			Increments: []int64{increments[0], increments[1]},
+			TokenCost:  costs[buttonIndex],
+			MaxPresses: maxPresses,
+		}
+	}
+	return &GeneralClawMachine{
+		buttons: buttons,
+		prize:   []int64{machine.prizeCoordinates[0], machine.prizeCoordinates[1]},
+	}
+}
+
+// Add the unit conversion fix to the prize, the same amount along every axis
+func (machine *GeneralClawMachine) FixUnitConversion() {
+	for axis := range machine.prize {
+		machine.prize[axis] += int64(unitConversionFix.AtVec(0))
+	}
+}
+
+func (machine *GeneralClawMachine) NumButtons() int {
+	return len(machine.buttons)
+}
+
+// Check the presses respect every press limit, and get their total token cost
+func (machine *GeneralClawMachine) pressesCost(presses []int64) (int64, error) {
+	totalCost := int64(0)
+	for buttonIndex, button := range machine.buttons {
+		if presses[buttonIndex] < 0 {
+			return 0, fmt.Errorf("%w: button %d pressed %d times", ErrorNegativePresses, buttonIndex, presses[buttonIndex])
+		}
+		if button.MaxPresses != NO_PRESS_LIMIT && presses[buttonIndex] > button.MaxPresses {
+			return 0, fmt.Errorf("%w: button %d pressed %d times but allows %d", ErrorPressLimitExceeded, buttonIndex, presses[buttonIndex], button.MaxPresses)
+		}
+		buttonCost, ok := mulInt64(presses[buttonIndex], button.TokenCost)
+		if !ok || totalCost+buttonCost < totalCost {
+			return 0, ErrorIntegerOverflow
+		}
+		totalCost += buttonCost
+	}
+	return totalCost, nil
+}
+
+// Find the presses of each button reaching the prize for the fewest tokens, and that number of tokens.
+//
+// When there are as many independent buttons as dimensions the presses are unique, and are found exactly
+// by Gaussian elimination over the rationals. Otherwise a bounded search is used, visiting at most maxStates
+// partial combinations of presses. The search is only practical for small prizes or press limits; two collinear
+// buttons with a large prize are better solved by ClawMachine.ComputeLowestTokenCost.
+func (machine *GeneralClawMachine) ComputeLowestTokenCost(maxStates int) ([]int64, int64, error) {
+	if presses, ok, err := machine.uniquePresses(); ok {
+		if err != nil {
+			return nil, 0, err
+		}
+		cost, err := machine.pressesCost(presses)
+		return presses, cost, err
+	}
+	return machine.searchPresses(maxStates)
+}
+
+// Solve the system directly if the increments form a square, non-singular matrix.
+// Returns false if the system is not of this form.
+func (machine *GeneralClawMachine) uniquePresses() ([]int64, bool, error) {
+	dimension := len(machine.prize)
+	if len(machine.buttons) != dimension || dimension == 0 {
+		return nil, false, nil
+	}
+
+	// Augmented matrix with a row per axis and a column per button, then the prize
+	rows := make([][]*big.Rat, dimension)
+	for axis := range dimension {
+		rows[axis] = make([]*big.Rat, dimension+1)
+		for buttonIndex, button := range machine.buttons {
+			rows[axis][buttonIndex] = new(big.Rat).SetInt64(button.Increments[axis])
+		}
+		rows[axis][dimension] = new(big.Rat).SetInt64(machine.prize[axis])
+	}
+
+	for column := range dimension {
+		pivotRow := -1
+		for row := column; row < dimension; row += 1 {
+			if rows[row][column].Sign() != 0 {
+				pivotRow = row
+				break
+			}
+		}
+		if pivotRow == -1 {
+			return nil, false, nil
+		}
+		rows[column], rows[pivotRow] = rows[pivotRow], rows[column]
+
+		pivot := new(big.Rat).Set(rows[column][column])
+		for entry := column; entry <= dimension; entry += 1 {
+			rows[column][entry].Quo(rows[column][entry], pivot)
+		}
+		for row := range dimension {
+			if row == column || rows[row][column].Sign() == 0 {
+				continue
+			}
+			factor := new(big.Rat).Set(rows[row][column])
+			for entry := column; entry <= dimension; entry += 1 {
+				rows[row][entry].Sub(rows[row][entry], new(big.Rat).Mul(factor, rows[column][entry]))
+			}
+		}
+	}
+
+	presses := make([]int64, dimension)
+	for buttonIndex := range dimension {
+		solution := rows[buttonIndex][dimension]
+		if !solution.IsInt() {
+			return nil, true, ErrorNonIntegralPresses
+		}
+		if !solution.Num().IsInt64() {
+			return nil, true, ErrorIntegerOverflow
+		}
+		presses[buttonIndex] = solution.Num().Int64()
+	}
+	return presses, true, nil
+}
+
+// Get the most times a button can usefully be pressed: its press limit if it has one, or otherwise the tightest
+// bound from any axis on which no button moves backwards, since presses on that axis can never be undone.
+// A button that does not move the claw is never worth pressing, as token costs are non-negative.
+// Returns false if the button is unbounded.
+func (machine *GeneralClawMachine) pressBound(buttonIndex int) (int64, bool) {
+	if !slices.ContainsFunc(machine.buttons[buttonIndex].Increments, func(increment int64) bool { return increment != 0 }) {
+		return 0, true
+	}
+
+	bound := int64(math.MaxInt64)
+	if limit := machine.buttons[buttonIndex].MaxPresses; limit != NO_PRESS_LIMIT {
+		bound = limit
+	}
+
+	for _, axis := range machine.monotoneAxes() {
+		if increment := machine.buttons[buttonIndex].Increments[axis]; increment > 0 {
+			bound = min(bound, max(machine.prize[axis], 0)/increment)
+		}
+	}
+	return bound, bound != math.MaxInt64
+}
+
+// Get the axes along which no button moves the claw backwards
+func (machine *GeneralClawMachine) monotoneAxes() []int {
+	axes := make([]int, 0)
+	for axis := range machine.prize {
+		axisMonotone := true
+		for _, button := range machine.buttons {
+			axisMonotone = axisMonotone && button.Increments[axis] >= 0
+		}
+		if axisMonotone {
+			axes = append(axes, axis)
+		}
+	}
+	return axes
+}
+
+// Check if the claw has passed the prize along an axis it can never move back along
+func (machine *GeneralClawMachine) overshootsMonotoneAxis(remaining []int64, monotoneAxes []int) bool {
+	for _, axis := range monotoneAxes {
+		if remaining[axis] < 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Find the cheapest presses by a depth first branch and bound search. Every button but the last has its presses
+// enumerated up to its bound, and the presses of the last button are then forced by the remaining distance.
+func (machine *GeneralClawMachine) searchPresses(maxStates int) ([]int64, int64, error) {
+	numButtons := len(machine.buttons)
+	if numButtons == 0 {
+		for _, target := range machine.prize {
+			if target != 0 {
+				return nil, 0, ErrorNoButtonCombination
+			}
+		}
+		return []int64{}, 0, nil
+	}
+
+	// Search buttons in an order where only the final, forced button may be unbounded
+	order := make([]int, 0, numButtons)
+	bounds := make([]int64, numButtons)
+	unboundedButton := -1
+	for buttonIndex := range numButtons {
+		bound, ok := machine.pressBound(buttonIndex)
+		bounds[buttonIndex] = bound
+		if ok {
+			order = append(order, buttonIndex)
+		} else if unboundedButton != -1 {
+			return nil, 0, ErrorUnboundedButtons
+		} else {
+			unboundedButton = buttonIndex
+		}
+	}
+	if unboundedButton != -1 {
+		order = append(order, unboundedButton)
+	}
+	lastButton := machine.buttons[order[numButtons-1]]
+	monotoneAxes := machine.monotoneAxes()
+
+	presses := make([]int64, numButtons)
+	remaining := append([]int64(nil), machine.prize...)
+	var bestPresses []int64
+	bestCost := int64(math.MaxInt64)
+	statesVisited := 0
+
+	var search func(depth int, cost int64) error
+	search = func(depth int, cost int64) error {
+		statesVisited += 1
+		if statesVisited > maxStates {
+			return ErrorSearchLimitExceeded
+		}
+		if cost >= bestCost {
+			return nil
+		}
+
+		if depth == numButtons-1 {
+			lastPresses, ok := forcedPresses(lastButton.Increments, remaining)
+			if !ok || lastPresses < 0 || (lastButton.MaxPresses != NO_PRESS_LIMIT && lastPresses > lastButton.MaxPresses) {
+				return nil
+			}
+			lastCost, ok := mulInt64(lastPresses, lastButton.TokenCost)
+			if !ok || cost+lastCost < cost || cost+lastCost >= bestCost {
+				return nil
+			}
+			presses[order[depth]] = lastPresses
+			bestPresses = append(bestPresses[:0], presses...)
+			bestCost = cost + lastCost
+			return nil
+		}
+
+		button := machine.buttons[order[depth]]
+		buttonPresses := int64(0)
+		for ; buttonPresses <= bounds[order[depth]]; buttonPresses += 1 {
+			// Further presses only overshoot a monotone axis more, or cost more
+			pressesCost := cost + buttonPresses*button.TokenCost
+			if machine.overshootsMonotoneAxis(remaining, monotoneAxes) || pressesCost >= bestCost {
+				break
+			}
+			presses[order[depth]] = buttonPresses
+			if err := search(depth+1, pressesCost); err != nil {
+				return err
+			}
+			for axis, increment := range button.Increments {
+				remaining[axis] -= increment
+			}
+		}
+		for axis, increment := range button.Increments {
+			remaining[axis] += buttonPresses * increment
+		}
+		return nil
+	}
+
+	if err := search(0, 0); err != nil {
+		return nil, 0, err
+	}
+	if bestPresses == nil {
+		return nil, 0, ErrorNoButtonCombination
+	}
+	return bestPresses, bestCost, nil
+}
+
+// Get the presses of a button covering exactly the remaining distance on every axis, or false if there are none
+func forcedPresses(increments []int64, remaining []int64) (int64, bool) {
+	presses := int64(0)
+	found := false
+	for axis, increment := range increments {
+		if increment == 0 {
+			if remaining[axis] != 0 {
+				return 0, false
+			}
+			continue
+		}
+		if remaining[axis]%increment != 0 {
+			return 0, false
+		}
+		axisPresses := remaining[axis] / increment
+		if found && axisPresses != presses {
+			return 0, false
+		}
+		presses, found = axisPresses, true
+	}
+	return presses, true
+}
@@ -0,0 +1,168 @@
+package clawmachine
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+//go:generate stringer --type InfeasibleReason
+type InfeasibleReason int
+
+const (
+	INFEASIBLE_REASON_NONE             InfeasibleReason = 0
+	INFEASIBLE_REASON_NON_INTEGRAL     InfeasibleReason = 1
+	INFEASIBLE_REASON_NEGATIVE_PRESSES InfeasibleReason = 2
+	INFEASIBLE_REASON_SINGULAR_MATRIX  InfeasibleReason = 3
+	INFEASIBLE_REASON_OVERFLOW         InfeasibleReason = 4
+
+	// Number of equal width bins the costs of winnable machines are split into by a report
+	REPORT_COST_HISTOGRAM_BINS int = 10
+
+	// Length of the bar of the largest histogram bin when a report is printed
+	REPORT_HISTOGRAM_BAR_WIDTH int = 40
+)
+
+// The button presses reaching the prize for the fewest tokens, or the reason no presses reach it
+type Solution struct {
+	PressesA int64
+	PressesB int64
+	Cost     int64
+	Feasible bool
+	Reason   InfeasibleReason
+}
+
+// Get the reason matching an error from the exact solver
+func infeasibleReasonOf(err error) InfeasibleReason {
+	switch {
+	case errors.Is(err, ErrorNonIntegralPresses):
+		return INFEASIBLE_REASON_NON_INTEGRAL
+	case errors.Is(err, ErrorNegativePresses):
+		return INFEASIBLE_REASON_NEGATIVE_PRESSES
+	case errors.Is(err, ErrorIntegerOverflow):
+		return INFEASIBLE_REASON_OVERFLOW
+	default:
+		// The only remaining failures are collinear buttons that miss the prize
+		return INFEASIBLE_REASON_SINGULAR_MATRIX
+	}
+}
+
+// Find the cheapest button presses reaching the prize, using the exact integer solver
+func (machine *ClawMachine) Solve() Solution {
+	presses, err := machine.solveButtonPresses()
+	if err != nil {
+		return Solution{Reason: infeasibleReasonOf(err)}
+	}
+	cost, err := pressesTokenCost(presses)
+	if err != nil {
+		return Solution{Reason: infeasibleReasonOf(err)}
+	}
+	return Solution{
+		PressesA: presses[0],
+		PressesB: presses[1],
+		Cost:     cost,
+		Feasible: true,
+		Reason:   INFEASIBLE_REASON_NONE,
+	}
+}
+
+// ------------------------------------------------------------------------------------------------------------------------
+
+// A bin of the cost histogram, counting winnable machines with cost in [MinCost, MaxCost]
+type CostHistogramBin struct {
+	MinCost     int64
+	MaxCost     int64
+	NumMachines int
+}
+
+// A summary of the solutions to every machine of an input
+type Report struct {
+	NumMachines int
+	NumWinnable int
+	TotalCost   int64
+	MinCost     int64
+	MedianCost  int64
+	MeanCost    float64
+	MaxCost     int64
+	CostBins    []CostHistogramBin
+	Infeasible  map[InfeasibleReason][]int
+	NumPressesA int64
+	NumPressesB int64
+}
+
+// Summarize the solutions, which are indexed by machine
+func NewReport(solutions []Solution) Report {
+	report := Report{
+		NumMachines: len(solutions),
+		Infeasible:  make(map[InfeasibleReason][]int),
+	}
+
+	costs := make([]int64, 0, len(solutions))
+	for machineIndex, solution := range solutions {
+		if !solution.Feasible {
+			report.Infeasible[solution.Reason] = append(report.Infeasible[solution.Reason], machineIndex)
+			continue
+		}
+		report.NumWinnable += 1
+		report.TotalCost += solution.Cost
+		report.NumPressesA += solution.PressesA
+		report.NumPressesB += solution.PressesB
+		costs = append(costs, solution.Cost)
+	}
+	if len(costs) == 0 {
+		return report
+	}
+
+	slices.Sort(costs)
+	report.MinCost = costs[0]
+	report.MaxCost = costs[len(costs)-1]
+	report.MedianCost = costs[len(costs)/2]
+	report.MeanCost = float64(report.TotalCost) / float64(len(costs))
+
+	// Bins split the cost range evenly, with any remainder going to the final bin
+	binWidth := max(1, int64(math.Ceil(float64(report.MaxCost-report.MinCost+1)/float64(REPORT_COST_HISTOGRAM_BINS))))
+	numBins := int((report.MaxCost-report.MinCost)/binWidth) + 1
+	report.CostBins = make([]CostHistogramBin, numBins)
+	for binIndex := range report.CostBins {
+		binMinCost := report.MinCost + int64(binIndex)*binWidth
+		report.CostBins[binIndex] = CostHistogramBin{
+			MinCost: binMinCost,
+			MaxCost: min(binMinCost+binWidth-1, report.MaxCost),
+		}
+	}
+	for _, cost := range costs {
+		report.CostBins[(cost-report.MinCost)/binWidth].NumMachines += 1
+	}
+	return report
+}
+
+func (report Report) String() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "%d of %d machines winnable, for %d tokens in total (%d presses of A, %d of B)\n",
+		report.NumWinnable, report.NumMachines, report.TotalCost, report.NumPressesA, report.NumPressesB)
+
+	if report.NumWinnable > 0 {
+		fmt.Fprintf(&builder, "Cost min %d, median %d, mean %.2f, max %d\n", report.MinCost, report.MedianCost, report.MeanCost, report.MaxCost)
+		largestBin := 0
+		for _, bin := range report.CostBins {
+			largestBin = max(largestBin, bin.NumMachines)
+		}
+		for _, bin := range report.CostBins {
+			barLength := bin.NumMachines * REPORT_HISTOGRAM_BAR_WIDTH / largestBin
+			binLine := fmt.Sprintf("  %20d - %-20d %5d %s", bin.MinCost, bin.MaxCost, bin.NumMachines, strings.Repeat("#", barLength))
+			builder.WriteString(strings.TrimRight(binLine, " ") + "\n")
+		}
+	}
+
+	reasons := make([]InfeasibleReason, 0, len(report.Infeasible))
+	for reason := range report.Infeasible {
+		reasons = append(reasons, reason)
+	}
+	slices.Sort(reasons)
+	for _, reason := range reasons {
+		fmt.Fprintf(&builder, "%d infeasible with %v: machines %v\n", len(report.Infeasible[reason]), reason, report.Infeasible[reason])
+	}
+	return builder.String()
+}
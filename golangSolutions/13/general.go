@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"hmcalister/AdventOfCode/clawmachine"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrorInvalidButtonCosts   error = errors.New("button costs must be a comma separated list of integers")
+	ErrorAxesMismatch         error = errors.New("button and prize lines name different axes")
+	ErrorNotEnoughButtonCosts error = errors.New("fewer token costs given than buttons")
+
+	generalButtonRegex = regexp.MustCompile(`^Button \w+:`)
+	generalPrizeRegex  = regexp.MustCompile(`^Prize:`)
+	buttonAxisRegex    = regexp.MustCompile(`([A-Z])([+-]\d+)`)
+	prizeAxisRegex     = regexp.MustCompile(`([A-Z])=([+-]?\d+)`)
+)
+
+func parseButtonCosts(buttonCostsStr string) ([]int64, error) {
+	costStrs := strings.Split(buttonCostsStr, ",")
+	costs := make([]int64, len(costStrs))
+	for costIndex, costStr := range costStrs {
+		cost, err := strconv.ParseInt(strings.TrimSpace(costStr), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrorInvalidButtonCosts, buttonCostsStr)
+		}
+		costs[costIndex] = cost
+	}
+	return costs, nil
+}
+
+// Parse a line of axis names and values, such as "X+94, Y+34" or "X=8400, Y=5400"
+func parseAxisValues(line string, axisRegex *regexp.Regexp) ([]string, []int64, error) {
+	matches := axisRegex.FindAllStringSubmatch(line, -1)
+	axes := make([]string, len(matches))
+	values := make([]int64, len(matches))
+	for matchIndex, match := range matches {
+		value, err := strconv.ParseInt(match[2], 10, 64)
+		if err != nil {
+			return nil, nil, err
+		}
+		axes[matchIndex] = match[1]
+		values[matchIndex] = value
+	}
+	return axes, values, nil
+}
+
+// Parse claw machines with any number of button lines followed by a prize line, each with any number of axes.
+//
+// The i-th button of each machine costs the i-th of the given costs, and may be pressed at most maxPresses times.
+func parseInputToGeneralClawMachines(fileScanner *bufio.Scanner, buttonCosts []int64, maxPresses int64) ([]*clawmachine.GeneralClawMachine, error) {
+	machines := make([]*clawmachine.GeneralClawMachine, 0)
+	buttons := make([]clawmachine.Button, 0)
+	var buttonAxes []string
+
+	for fileScanner.Scan() {
+		line := strings.TrimSpace(fileScanner.Text())
+		switch {
+		case generalButtonRegex.MatchString(line):
+			axes, increments, err := parseAxisValues(line, buttonAxisRegex)
+			if err != nil {
+				return nil, err
+			}
+			if len(buttons) >= len(buttonCosts) {
+				return nil, fmt.Errorf("%w: %d costs for line %q", ErrorNotEnoughButtonCosts, len(buttonCosts), line)
+			}
+			if buttonAxes != nil && strings.Join(axes, ",") != strings.Join(buttonAxes, ",") {
+				return nil, fmt.Errorf("%w: %q", ErrorAxesMismatch, line)
+			}
+			buttonAxes = axes
+			buttons = append(buttons, clawmachine.Button{
+				Increments: increments,
+				TokenCost:  buttonCosts[len(buttons)],
+				MaxPresses: maxPresses,
+			})
+
+		case generalPrizeRegex.MatchString(line):
+			axes, prize, err := parseAxisValues(line, prizeAxisRegex)
+			if err != nil {
+				return nil, err
+			}
+			if strings.Join(axes, ",") != strings.Join(buttonAxes, ",") {
+				return nil, fmt.Errorf("%w: %q", ErrorAxesMismatch, line)
+			}
+			machine, err := clawmachine.NewGeneralClawMachine(buttons, prize)
+			if err != nil {
+				return nil, err
+			}
+			slog.Debug("input parsed to general claw machine", "claw machine", machine)
+			machines = append(machines, machine)
+			buttons = make([]clawmachine.Button, 0)
+			buttonAxes = nil
+		}
+	}
+
+	return machines, nil
+}
+
+// Sum the lowest token cost over every winnable machine
+func totalGeneralTokenCost(machines []*clawmachine.GeneralClawMachine) int {
+	totalCost := int64(0)
+	validMachines := 0
+	for _, machine := range machines {
+		presses, cost, err := machine.ComputeLowestTokenCost(maxSearchStates)
+		if err != nil {
+			slog.Error("error when computing token cost", "error", err)
+			continue
+		}
+		totalCost += cost
+		validMachines += 1
+		slog.Debug("found lowest token cost", "presses", presses, "current machine token cost", cost, "updated total token cost", totalCost)
+	}
+	slog.Debug("finished computing claw results", "total claw machines", len(machines), "valid claw machines", validMachines)
+	return int(totalCost)
+}
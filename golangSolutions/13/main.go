@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"errors"
 	"flag"
+	"fmt"
 	"hmcalister/AdventOfCode/clawmachine"
 	"log/slog"
 	"os"
@@ -17,11 +18,26 @@ const (
 	CPU_PROFILE_FILEPATH string = "profile"
 )
 
+var (
+	useFloatSolver  bool
+	generalMachines bool
+	buttonCostsStr  string
+	pressLimit      int64
+	maxSearchStates int
+	printReport     bool
+)
+
 func main() {
 	debugFlag := flag.Bool("debug", false, "Debug Flag")
 	inputFilePath := flag.String("inputFile", "puzzleInput", "Path to input file.")
 	selectedPart := flag.Int("part", 0, "Part to execute. Must be 1 or 2.")
 	profile := flag.Bool("profile", false, "Flag to profile program")
+	flag.BoolVar(&useFloatSolver, "floatSolver", false, "Solve machines in floating point rather than with exact integer arithmetic.")
+	flag.BoolVar(&generalMachines, "general", false, "Parse machines with any number of buttons and axes, and solve them with the general solver.")
+	flag.StringVar(&buttonCostsStr, "buttonCosts", "3,1", "Comma separated token cost of each button, in order, for general machines.")
+	flag.Int64Var(&pressLimit, "pressLimit", clawmachine.NO_PRESS_LIMIT, "Most times each button of a general machine may be pressed. Negative for no limit.")
+	flag.IntVar(&maxSearchStates, "maxSearchStates", clawmachine.DEFAULT_MAX_SEARCH_STATES, "Most partial press combinations the general solver may visit for each machine.")
+	flag.BoolVar(&printReport, "report", false, "Print the button presses of every machine, and a summary of costs and infeasible machines.")
 	flag.Parse()
 	if *profile {
 		f, err := os.Create(CPU_PROFILE_FILEPATH)
@@ -143,12 +159,63 @@ func parseInputToClawMachines(fileScanner *bufio.Scanner) []*clawmachine.ClawMac
 	return machines
 }
 
+// Compute the lowest token cost of the machine with the selected solver
+func computeLowestTokenCost(machine *clawmachine.ClawMachine) (int, error) {
+	if useFloatSolver {
+		return machine.ComputeLowestTokenCostFloat()
+	}
+	return machine.ComputeLowestTokenCost()
+}
+
+// Parse and solve general machines, optionally fixing their unit conversion first
+func solveGeneralMachines(fileScanner *bufio.Scanner, fixUnitConversion bool) (int, error) {
+	buttonCosts, err := parseButtonCosts(buttonCostsStr)
+	if err != nil {
+		return 0, err
+	}
+	machines, err := parseInputToGeneralClawMachines(fileScanner, buttonCosts, max(pressLimit, clawmachine.NO_PRESS_LIMIT))
+	if err != nil {
+		return 0, err
+	}
+	if fixUnitConversion {
+		for _, machine := range machines {
+			machine.FixUnitConversion()
+		}
+	}
+	return totalGeneralTokenCost(machines), nil
+}
+
+// Solve every machine, printing each solution and then a summary report. Returns the total cost of winnable machines.
+func reportClawMachines(clawMachines []*clawmachine.ClawMachine) int {
+	solutions := make([]clawmachine.Solution, len(clawMachines))
+	for machineIndex, machine := range clawMachines {
+		solution := machine.Solve()
+		solutions[machineIndex] = solution
+		if solution.Feasible {
+			fmt.Printf("Machine %d: %d presses of A, %d presses of B, %d tokens\n", machineIndex, solution.PressesA, solution.PressesB, solution.Cost)
+		} else {
+			fmt.Printf("Machine %d: infeasible, %v\n", machineIndex, solution.Reason)
+		}
+	}
+
+	report := clawmachine.NewReport(solutions)
+	fmt.Print(report)
+	return int(report.TotalCost)
+}
+
 func Part01(fileScanner *bufio.Scanner) (int, error) {
+	if generalMachines {
+		return solveGeneralMachines(fileScanner, false)
+	}
+
 	clawMachines := parseInputToClawMachines(fileScanner)
+	if printReport {
+		return reportClawMachines(clawMachines), nil
+	}
 	totalCost := 0
 	validMachines := 0
 	for _, machine := range clawMachines {
-		cost, err := machine.ComputeLowestTokenCost()
+		cost, err := computeLowestTokenCost(machine)
 		if err != nil {
 			slog.Error("error when computing token cost", "error", err)
 			continue
@@ -163,12 +230,22 @@ func Part01(fileScanner *bufio.Scanner) (int, error) {
 }
 
 func Part02(fileScanner *bufio.Scanner) (int, error) {
+	if generalMachines {
+		return solveGeneralMachines(fileScanner, true)
+	}
+
 	clawMachines := parseInputToClawMachines(fileScanner)
+	if printReport {
+		for _, machine := range clawMachines {
+			machine.FixUnitConversion()
+		}
+		return reportClawMachines(clawMachines), nil
+	}
 	totalCost := 0
 	validMachines := 0
 	for _, machine := range clawMachines {
 		machine.FixUnitConversion()
-		cost, err := machine.ComputeLowestTokenCost()
+		cost, err := computeLowestTokenCost(machine)
 		if err != nil {
 			slog.Error("error when computing token cost", "error", err)
 			continue
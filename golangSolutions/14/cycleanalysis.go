@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"hmcalister/AdventOfCode/robot"
+	"log/slog"
+	"math"
+)
+
+var (
+	ErrorRobotIndexOutOfRange error = errors.New("robot index out of range")
+)
+
+// Print the period of the selected robot, the first step it is in each quadrant, and its earliest collision
+// with every other robot, all without simulating any steps. Returns the period of the robot.
+func reportRobotCycles(robots []*robot.Robot, gridX, gridY int, robotIndex int) (int, error) {
+	if robotIndex < 0 || robotIndex >= len(robots) {
+		return 0, fmt.Errorf("%w: %d of %d robots", ErrorRobotIndexOutOfRange, robotIndex, len(robots))
+	}
+	selectedRobot := robots[robotIndex]
+	robotPeriod := selectedRobot.Period(gridX, gridY)
+	fmt.Printf("Global period: %d\n", robot.GlobalPeriod(gridX, gridY))
+	fmt.Printf("Robot %d period: %d\n", robotIndex, robotPeriod)
+
+	for quadrantIndex := range 4 {
+		quadrant, err := robot.Quadrant(quadrantIndex, gridX, gridY)
+		if err != nil {
+			return 0, err
+		}
+		firstStep, err := selectedRobot.FirstStepInRegion(quadrant, gridX, gridY, 0)
+		if errors.Is(err, robot.ErrorNeverInRegion) {
+			fmt.Printf("Quadrant %d: never entered\n", quadrantIndex)
+			continue
+		} else if err != nil {
+			return 0, err
+		}
+		fmt.Printf("Quadrant %d: first entered at step %d\n", quadrantIndex, firstStep)
+	}
+
+	numCollidingRobots := 0
+	earliestCollisionStep := math.MaxInt
+	for otherIndex, otherRobot := range robots {
+		if otherIndex == robotIndex {
+			continue
+		}
+		collision, err := selectedRobot.CollisionWith(otherRobot, gridX, gridY)
+		if err != nil {
+			continue
+		}
+		slog.Debug("found collision", "robot index", robotIndex, "other index", otherIndex, "first step", collision.FirstStep, "period", collision.Period)
+		numCollidingRobots += 1
+		earliestCollisionStep = min(earliestCollisionStep, collision.FirstStep)
+	}
+	if numCollidingRobots == 0 {
+		fmt.Printf("Robot %d never shares a position with another robot\n", robotIndex)
+	} else {
+		fmt.Printf("Robot %d shares a position with %d other robots, first at step %d\n", robotIndex, numCollidingRobots, earliestCollisionStep)
+	}
+
+	return robotPeriod, nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"hmcalister/AdventOfCode/gridrender"
+	"hmcalister/AdventOfCode/gridutils"
+	"hmcalister/AdventOfCode/robot"
+	"image/color"
+	"image/png"
+	"log/slog"
+	"os"
+
+	hashset "github.com/hmcalister/Go-DSA/set/HashSet"
+)
+
+const (
+	// Number of distinct colors in the heatmap gradient
+	HEATMAP_COLOR_LEVELS int = 64
+)
+
+var (
+	ErrorInvalidStepRange error = errors.New("export step range is empty or stride is not positive")
+
+	// The heatmap fades from empty cells through robot cells to white for the most visited cells
+	heatmapGradientStops = []color.RGBA{emptyCellStyle.Color, robotCellStyle.Color, {255, 255, 255, 255}}
+)
+
+// Write any requested animation or heatmap of the robots
+func exportRobots(robots []*robot.Robot, gridX, gridY int) error {
+	if animationFilePath != "" {
+		if err := exportAnimation(robots, gridX, gridY); err != nil {
+			return err
+		}
+	}
+	if heatmapFilePath != "" {
+		if err := exportHeatmap(robots, gridX, gridY); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Check the steps from animationStartStep up to (but not including) animationEndStep, taking every
+// animationStride-th step, form a non-empty range
+func checkExportStepRange() error {
+	if animationEndStep <= animationStartStep || animationStride <= 0 {
+		return fmt.Errorf("%w: steps %d to %d with stride %d", ErrorInvalidStepRange, animationStartStep, animationEndStep, animationStride)
+	}
+	return nil
+}
+
+// Write an animated GIF of the robots over the export step range
+func exportAnimation(robots []*robot.Robot, gridX, gridY int) error {
+	if err := checkExportStepRange(); err != nil {
+		return err
+	}
+
+	animationFile, err := os.Create(animationFilePath)
+	if err != nil {
+		return err
+	}
+	defer animationFile.Close()
+
+	animation, err := gridrender.NewAnimation(animationFile, gridX, gridY, exportCellSize, animationFramesPerSecond, gridrender.Palette(emptyCellStyle, robotCellStyle))
+	if err != nil {
+		return err
+	}
+
+	numFrames := 0
+	for step := animationStartStep; step < animationEndStep; step += animationStride {
+		robotInCoordinate := hashset.New[robot.Vector2]()
+		for _, position := range robot.ComputePositions(robots, gridX, gridY, step) {
+			robotInCoordinate.Add(position)
+		}
+		if err := animation.AddFrame(robotOccupancyCanvas(gridX, gridY, robotInCoordinate)); err != nil {
+			return err
+		}
+		numFrames += 1
+	}
+	if err := animation.Close(); err != nil {
+		return err
+	}
+
+	slog.Info("wrote robot animation", "file", animationFilePath, "frames", numFrames, "start step", animationStartStep, "end step", animationEndStep, "stride", animationStride)
+	return nil
+}
+
+// Get the color a fraction of the way along the heatmap gradient
+func heatmapColor(fraction float64) color.RGBA {
+	scaledFraction := fraction * float64(len(heatmapGradientStops)-1)
+	stopIndex := min(int(scaledFraction), len(heatmapGradientStops)-2)
+	stopFraction := scaledFraction - float64(stopIndex)
+	from, to := heatmapGradientStops[stopIndex], heatmapGradientStops[stopIndex+1]
+	interpolate := func(a, b uint8) uint8 {
+		return uint8(float64(a) + stopFraction*(float64(b)-float64(a)) + 0.5)
+	}
+	return color.RGBA{interpolate(from.R, to.R), interpolate(from.G, to.G), interpolate(from.B, to.B), 255}
+}
+
+// Write a PNG showing how often each cell is occupied over the export step range.
+// Counts are scaled between the least and most visited cells, so cells visited equally often share a color.
+//
+// The range should be short compared to the period of the robots: over a full period every moving robot
+// visits every cell equally often when the grid sides are coprime, as they are for the puzzle, so the heatmap
+// would be a single color.
+func exportHeatmap(robots []*robot.Robot, gridX, gridY int) error {
+	if err := checkExportStepRange(); err != nil {
+		return err
+	}
+
+	occupancyCounts := make([]int, gridX*gridY)
+	numSteps := 0
+	for step := animationStartStep; step < animationEndStep; step += animationStride {
+		numSteps += 1
+		for _, position := range robot.ComputePositions(robots, gridX, gridY, step) {
+			occupancyCounts[position.Y*gridX+position.X] += 1
+		}
+	}
+
+	minCount, maxCount := occupancyCounts[0], occupancyCounts[0]
+	for _, count := range occupancyCounts {
+		minCount = min(minCount, count)
+		maxCount = max(maxCount, count)
+	}
+
+	heatmapStyles := make([]gridrender.CellStyle, HEATMAP_COLOR_LEVELS)
+	for level := range heatmapStyles {
+		heatmapStyles[level] = gridrender.CellStyle{Color: heatmapColor(float64(level) / float64(HEATMAP_COLOR_LEVELS-1))}
+	}
+	heatmapCanvas := gridrender.NewCanvas(gridrender.FuncGrid{
+		GridWidth:  gridX,
+		GridHeight: gridY,
+		StyleFunc: func(coordinate gridutils.Coordinate) gridrender.CellStyle {
+			if maxCount == minCount {
+				return heatmapStyles[0]
+			}
+			count := occupancyCounts[coordinate.Y*gridX+coordinate.X]
+			return heatmapStyles[(count-minCount)*(HEATMAP_COLOR_LEVELS-1)/(maxCount-minCount)]
+		},
+	})
+
+	heatmapFile, err := os.Create(heatmapFilePath)
+	if err != nil {
+		return err
+	}
+	defer heatmapFile.Close()
+	if err := png.Encode(heatmapFile, gridrender.RenderImage(heatmapCanvas, exportCellSize, gridrender.Palette(heatmapStyles...))); err != nil {
+		return err
+	}
+
+	slog.Info("wrote robot heatmap", "file", heatmapFilePath, "start step", animationStartStep, "end step", animationEndStep, "stride", animationStride, "steps", numSteps, "least visits", minCount, "most visits", maxCount)
+	return nil
+}
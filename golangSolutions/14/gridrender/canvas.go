@@ -0,0 +1,168 @@
+package gridrender
+
+import (
+	"hmcalister/AdventOfCode/gridutils"
+	"image/color"
+
+	hashset "github.com/hmcalister/Go-DSA/set/HashSet"
+)
+
+// How a single cell is drawn in each output format
+type CellStyle struct {
+	// The rune printed for the cell in text output
+	Rune rune
+
+	// SGR parameters used to color the rune in ANSI output, such as "32" for green or "1;31" for bold red.
+	// Empty for no styling.
+	ANSIStyle string
+
+	// The color the cell is filled with in image output
+	Color color.RGBA
+}
+
+// Anything that can be rendered: a rectangular grid with a style for each cell
+type Grid interface {
+	Width() int
+	Height() int
+	CellStyle(coordinate gridutils.Coordinate) CellStyle
+}
+
+// Adapts a function giving the style of each cell into a Grid
+type FuncGrid struct {
+	GridWidth  int
+	GridHeight int
+	StyleFunc  func(coordinate gridutils.Coordinate) CellStyle
+}
+
+func (grid FuncGrid) Width() int {
+	return grid.GridWidth
+}
+
+func (grid FuncGrid) Height() int {
+	return grid.GridHeight
+}
+
+func (grid FuncGrid) CellStyle(coordinate gridutils.Coordinate) CellStyle {
+	return grid.StyleFunc(coordinate)
+}
+
+// ------------------------------------------------------------------------------------------------------------------------
+
+// The resolved style of every cell in a grid, after applying overlays. Every output format renders from a canvas.
+type Canvas struct {
+	width  int
+	height int
+	cells  []CellStyle
+}
+
+// Resolve the style of every cell in the grid, then apply each overlay in order (so later overlays are drawn on top)
+func NewCanvas(grid Grid, overlays ...Overlay) *Canvas {
+	canvas := &Canvas{
+		width:  grid.Width(),
+		height: grid.Height(),
+		cells:  make([]CellStyle, grid.Width()*grid.Height()),
+	}
+	for y := range canvas.height {
+		for x := range canvas.width {
+			canvas.cells[y*canvas.width+x] = grid.CellStyle(gridutils.Coordinate{X: x, Y: y})
+		}
+	}
+	for _, overlay := range overlays {
+		overlay.Apply(canvas)
+	}
+	return canvas
+}
+
+func (canvas *Canvas) Width() int {
+	return canvas.width
+}
+
+func (canvas *Canvas) Height() int {
+	return canvas.height
+}
+
+func (canvas *Canvas) InBounds(coordinate gridutils.Coordinate) bool {
+	return 0 <= coordinate.X && coordinate.X < canvas.width && 0 <= coordinate.Y && coordinate.Y < canvas.height
+}
+
+// Get the style of a cell. Cells outside the canvas have the zero style.
+func (canvas *Canvas) At(coordinate gridutils.Coordinate) CellStyle {
+	if !canvas.InBounds(coordinate) {
+		return CellStyle{}
+	}
+	return canvas.cells[coordinate.Y*canvas.width+coordinate.X]
+}
+
+// Set the style of a cell. Cells outside the canvas are ignored, so overlays need not clip themselves.
+func (canvas *Canvas) Set(coordinate gridutils.Coordinate, style CellStyle) {
+	if canvas.InBounds(coordinate) {
+		canvas.cells[coordinate.Y*canvas.width+coordinate.X] = style
+	}
+}
+
+// ------------------------------------------------------------------------------------------------------------------------
+
+// Anything drawn over a grid, such as a path or the positions of entities
+type Overlay interface {
+	Apply(canvas *Canvas)
+}
+
+// Draws each step of a path in the same style.
+//
+// If ShowDirection is set, each step after the first is drawn as an arrow pointing in the direction the path entered
+// that cell, keeping the style's colors.
+type PathOverlay struct {
+	Path          []gridutils.Coordinate
+	Style         CellStyle
+	ShowDirection bool
+}
+
+func (overlay PathOverlay) Apply(canvas *Canvas) {
+	for stepIndex, step := range overlay.Path {
+		style := overlay.Style
+		if overlay.ShowDirection && stepIndex > 0 {
+			previousStep := overlay.Path[stepIndex-1]
+			switch {
+			case step.Y < previousStep.Y:
+				style.Rune = '^'
+			case step.X > previousStep.X:
+				style.Rune = '>'
+			case step.Y > previousStep.Y:
+				style.Rune = 'v'
+			case step.X < previousStep.X:
+				style.Rune = '<'
+			}
+		}
+		canvas.Set(step, style)
+	}
+}
+
+// Highlights every cell in a set
+type SetOverlay struct {
+	Set   *hashset.HashSet[gridutils.Coordinate]
+	Style CellStyle
+}
+
+func (overlay SetOverlay) Apply(canvas *Canvas) {
+	for coordinate := range overlay.Set.Iterator() {
+		canvas.Set(coordinate, overlay.Style)
+	}
+}
+
+// Draws entities, each with its own style. Entities in the same cell are drawn in order, so the last one is shown.
+type EntityOverlay struct {
+	Positions []gridutils.Coordinate
+	Styles    []CellStyle
+}
+
+// Add an entity to the overlay
+func (overlay *EntityOverlay) Add(position gridutils.Coordinate, style CellStyle) {
+	overlay.Positions = append(overlay.Positions, position)
+	overlay.Styles = append(overlay.Styles, style)
+}
+
+func (overlay EntityOverlay) Apply(canvas *Canvas) {
+	for entityIndex, position := range overlay.Positions {
+		canvas.Set(position, overlay.Styles[entityIndex])
+	}
+}
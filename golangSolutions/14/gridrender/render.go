@@ -0,0 +1,172 @@
+package gridrender
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+)
+
+const (
+	FORMAT_ASCII string = "ascii"
+	FORMAT_ANSI  string = "ansi"
+	FORMAT_PNG   string = "png"
+
+	// Side length of each cell in pixels, when rendering images without a given cell size
+	DEFAULT_CELL_SIZE int = 8
+
+	ANSI_RESET_SEQUENCE string = "\033[0m"
+)
+
+var (
+	ErrorUnknownFormat error = errors.New("unknown render format")
+)
+
+// Render the canvas as plain text, one line per row
+func RenderASCII(canvas *Canvas) string {
+	var builder strings.Builder
+	builder.Grow(canvas.height * (canvas.width + 1))
+	for y := range canvas.height {
+		for _, style := range canvas.cells[y*canvas.width : (y+1)*canvas.width] {
+			builder.WriteRune(style.Rune)
+		}
+		builder.WriteRune('\n')
+	}
+	return builder.String()
+}
+
+// Render the canvas as text colored with ANSI escape sequences, one line per row.
+// Escape sequences are only emitted when the style changes, and the style is reset at the end of each row.
+func RenderANSI(canvas *Canvas) string {
+	var builder strings.Builder
+	for y := range canvas.height {
+		currentStyle := ""
+		for _, style := range canvas.cells[y*canvas.width : (y+1)*canvas.width] {
+			if style.ANSIStyle != currentStyle {
+				if currentStyle != "" {
+					builder.WriteString(ANSI_RESET_SEQUENCE)
+				}
+				if style.ANSIStyle != "" {
+					builder.WriteString("\033[" + style.ANSIStyle + "m")
+				}
+				currentStyle = style.ANSIStyle
+			}
+			builder.WriteRune(style.Rune)
+		}
+		if currentStyle != "" {
+			builder.WriteString(ANSI_RESET_SEQUENCE)
+		}
+		builder.WriteRune('\n')
+	}
+	return builder.String()
+}
+
+// Get the distinct colors of the given styles, in order of first appearance
+func Palette(styles ...CellStyle) color.Palette {
+	palette := make(color.Palette, 0)
+	seenColors := make(map[color.RGBA]bool)
+	for _, style := range styles {
+		if !seenColors[style.Color] {
+			seenColors[style.Color] = true
+			palette = append(palette, style.Color)
+		}
+	}
+	return palette
+}
+
+// Render the canvas as an image, filling each cell with its color.
+//
+// Colors are mapped onto the given palette, or onto the colors used by the canvas if the palette is nil.
+func RenderImage(canvas *Canvas, cellSize int, palette color.Palette) *image.Paletted {
+	if palette == nil {
+		palette = Palette(canvas.cells...)
+		if len(palette) > GIF_MAX_PALETTE_SIZE {
+			palette = palette[:GIF_MAX_PALETTE_SIZE]
+		}
+	}
+	img := image.NewPaletted(image.Rect(0, 0, cellSize*canvas.width, cellSize*canvas.height), palette)
+	renderInto(img, canvas, cellSize)
+	return img
+}
+
+func renderInto(img *image.Paletted, canvas *Canvas, cellSize int) {
+	paletteIndices := make(map[color.RGBA]uint8)
+	for y := range canvas.height {
+		for x := range canvas.width {
+			cellColor := canvas.cells[y*canvas.width+x].Color
+			paletteIndex, ok := paletteIndices[cellColor]
+			if !ok {
+				paletteIndex = uint8(img.Palette.Index(cellColor))
+				paletteIndices[cellColor] = paletteIndex
+			}
+			for pixelY := cellSize * y; pixelY < cellSize*(y+1); pixelY += 1 {
+				rowStart := img.PixOffset(cellSize*x, pixelY)
+				row := img.Pix[rowStart : rowStart+cellSize]
+				for pixelX := range row {
+					row[pixelX] = paletteIndex
+				}
+			}
+		}
+	}
+}
+
+// Render the canvas to the writer in the given format (one of FORMAT_ASCII, FORMAT_ANSI, or FORMAT_PNG).
+// The cell size is only used for images.
+func Render(writer io.Writer, canvas *Canvas, format string, cellSize int) error {
+	var err error
+	switch format {
+	case FORMAT_ASCII:
+		_, err = io.WriteString(writer, RenderASCII(canvas))
+	case FORMAT_ANSI:
+		_, err = io.WriteString(writer, RenderANSI(canvas))
+	case FORMAT_PNG:
+		err = png.Encode(writer, RenderImage(canvas, cellSize, nil))
+	default:
+		err = fmt.Errorf("%w: %q", ErrorUnknownFormat, format)
+	}
+	return err
+}
+
+// ------------------------------------------------------------------------------------------------------------------------
+
+// An animated GIF of canvases, streamed to a writer one frame at a time.
+//
+// The palette is fixed when the animation is created, so it should contain every color any frame will use.
+type Animation struct {
+	writer   *GIFWriter
+	frame    *image.Paletted
+	cellSize int
+	delay    int
+}
+
+// Start an animation of canvases of the given size, shown at the given frame rate
+func NewAnimation(writer io.Writer, width, height, cellSize, framesPerSecond int, palette color.Palette) (*Animation, error) {
+	frame := image.NewPaletted(image.Rect(0, 0, cellSize*width, cellSize*height), palette)
+	gifWriter, err := NewGIFWriter(writer, frame.Bounds().Dx(), frame.Bounds().Dy(), palette)
+	if err != nil {
+		return nil, err
+	}
+	return &Animation{
+		writer:   gifWriter,
+		frame:    frame,
+		cellSize: cellSize,
+		delay:    max(1, GIF_DELAY_UNITS_PER_SECOND/max(1, framesPerSecond)),
+	}, nil
+}
+
+// Render the canvas and write it as the next frame
+func (animation *Animation) AddFrame(canvas *Canvas) error {
+	if canvas.width*animation.cellSize != animation.frame.Bounds().Dx() || canvas.height*animation.cellSize != animation.frame.Bounds().Dy() {
+		return ErrorFrameSize
+	}
+	renderInto(animation.frame, canvas, animation.cellSize)
+	return animation.writer.WriteFrame(animation.frame, animation.delay)
+}
+
+// Finish the animation. The underlying writer is not closed.
+func (animation *Animation) Close() error {
+	return animation.writer.Close()
+}
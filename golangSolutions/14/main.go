@@ -5,7 +5,10 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"hmcalister/AdventOfCode/gridrender"
+	"hmcalister/AdventOfCode/gridutils"
 	"hmcalister/AdventOfCode/robot"
+	"image/color"
 	"log/slog"
 	"os"
 	"regexp"
@@ -21,11 +24,52 @@ const (
 	CPU_PROFILE_FILEPATH string = "profile"
 )
 
+var (
+	renderFormat    string
+	renderFilePath  string
+	easterEggMetric string
+	interactive     bool
+	analyzeRobot    int
+
+	animationFilePath        string
+	animationStartStep       int
+	animationEndStep         int
+	animationStride          int
+	animationFramesPerSecond int
+	heatmapFilePath          string
+	exportCellSize           int
+
+	ErrorUnknownEasterEggMetric error = errors.New("unknown easter egg metric")
+
+	// Metrics that can be selected to find the easter egg. The CRT search is handled separately.
+	easterEggMetrics = map[string]robot.FrameMetric{
+		"variance":  robot.FRAME_METRIC_VARIANCE,
+		"entropy":   robot.FRAME_METRIC_ENTROPY,
+		"component": robot.FRAME_METRIC_LARGEST_COMPONENT,
+		"safety":    robot.FRAME_METRIC_SAFETY_FACTOR,
+	}
+
+	emptyCellStyle = gridrender.CellStyle{Rune: '.', ANSIStyle: "2", Color: color.RGBA{16, 16, 16, 255}}
+	robotCellStyle = gridrender.CellStyle{Rune: '#', ANSIStyle: "1;32", Color: color.RGBA{80, 220, 80, 255}}
+)
+
 func main() {
 	debugFlag := flag.Bool("debug", false, "Debug Flag")
 	inputFilePath := flag.String("inputFile", "puzzleInput", "Path to input file.")
 	selectedPart := flag.Int("part", 0, "Part to execute. Must be 1 or 2.")
 	profile := flag.Bool("profile", false, "Flag to profile program")
+	flag.StringVar(&renderFormat, "renderFormat", gridrender.FORMAT_ASCII, "Format to render the robots in. One of ascii, ansi, or png.")
+	flag.StringVar(&renderFilePath, "renderFile", "", "File to render the robots to, rewritten for each render. Defaults to stdout.")
+	flag.StringVar(&easterEggMetric, "eggMetric", "crt", "Metric used to find the easter egg in part 2. One of crt, variance, entropy, component, or safety.")
+	flag.BoolVar(&interactive, "interactive", false, "Print every frame in part 2 without overlapping robots and wait for enter, rather than finding the easter egg automatically.")
+	flag.IntVar(&analyzeRobot, "analyzeRobot", -1, "Index of a robot to report the period, quadrant entry steps, and collisions of, in place of the selected part.")
+	flag.StringVar(&animationFilePath, "animationFile", "", "File to write an animated GIF of the robots to. No animation is written if empty.")
+	flag.IntVar(&animationStartStep, "animationStart", 0, "First step shown in the animation and counted in the heatmap.")
+	flag.IntVar(&animationEndStep, "animationEnd", 100, "Step the animation and heatmap stop before.")
+	flag.IntVar(&animationStride, "animationStride", 1, "Number of steps between animation frames and between steps counted in the heatmap.")
+	flag.IntVar(&animationFramesPerSecond, "fps", 10, "Frames per second of the animation.")
+	flag.StringVar(&heatmapFilePath, "heatmapFile", "", "File to write a PNG heatmap of robot occupancy over the -animationStart to -animationEnd steps to. No heatmap is written if empty.")
+	flag.IntVar(&exportCellSize, "cellSize", gridrender.DEFAULT_CELL_SIZE, "Side length in pixels of each cell in the animation and heatmap.")
 	flag.Parse()
 	if *profile {
 		f, err := os.Create(CPU_PROFILE_FILEPATH)
@@ -112,6 +156,20 @@ func parseInputToRobots(fileScanner *bufio.Scanner) []*robot.Robot {
 	return robots
 }
 
+// Render the canvas in the selected format, to the selected file or stdout
+func renderRobots(canvas *gridrender.Canvas) error {
+	if renderFilePath == "" {
+		return gridrender.Render(os.Stdout, canvas, renderFormat, gridrender.DEFAULT_CELL_SIZE)
+	}
+
+	renderFile, err := os.Create(renderFilePath)
+	if err != nil {
+		return err
+	}
+	defer renderFile.Close()
+	return gridrender.Render(renderFile, canvas, renderFormat, gridrender.DEFAULT_CELL_SIZE)
+}
+
 func Part01(fileScanner *bufio.Scanner) (int, error) {
 	if !fileScanner.Scan() {
 		slog.Error("no input found")
@@ -133,43 +191,43 @@ func Part01(fileScanner *bufio.Scanner) (int, error) {
 	robots := parseInputToRobots(fileScanner)
 	// slog.Debug("parsed input", "gridX", gridX, "gridY", gridY, "robots", robots)
 
-	quadrantCounts := []int{0, 0, 0, 0}
+	if err := exportRobots(robots, gridX, gridY); err != nil {
+		return 0, err
+	}
+
+	if analyzeRobot >= 0 {
+		return reportRobotCycles(robots, gridX, gridY, analyzeRobot)
+	}
+
+	positions := robot.ComputePositions(robots, gridX, gridY, 100)
 	coordinateCounts := make(map[robot.Vector2]int)
-	for _, robot := range robots {
-		nextPosition := robot.ComputePosition(gridX, gridY, 100)
-
-		if nextPosition.X < gridX/2 && nextPosition.Y < gridY/2 {
-			quadrantCounts[0] += 1
-		} else if nextPosition.X > gridX/2 && nextPosition.Y < gridY/2 {
-			quadrantCounts[1] += 1
-		} else if nextPosition.X < gridX/2 && nextPosition.Y > gridY/2 {
-			quadrantCounts[2] += 1
-		} else if nextPosition.X > gridX/2 && nextPosition.Y > gridY/2 {
-			quadrantCounts[3] += 1
-		}
+	for robotIndex, nextPosition := range positions {
 		coordinateCounts[nextPosition] += 1
-		slog.Debug("robot stepped", "robot", *robot, "next position", nextPosition, "updated quadrant counts", quadrantCounts)
+		slog.Debug("robot stepped", "robot", *robots[robotIndex], "next position", nextPosition)
 	}
 
-	for y := 0; y < gridY; y += 1 {
-		for x := 0; x < gridX; x += 1 {
-			coordinate := robot.Vector2{X: x, Y: y}
-			coordinateCount, ok := coordinateCounts[coordinate]
+	// Each cell shows the number of robots in it, or + for more than nine
+	countCanvas := gridrender.NewCanvas(gridrender.FuncGrid{
+		GridWidth:  gridX,
+		GridHeight: gridY,
+		StyleFunc: func(coordinate gridutils.Coordinate) gridrender.CellStyle {
+			coordinateCount, ok := coordinateCounts[robot.Vector2{X: coordinate.X, Y: coordinate.Y}]
 			if !ok {
-				fmt.Print(".")
-			} else {
-				fmt.Print(coordinateCount)
+				return emptyCellStyle
 			}
-		}
-		fmt.Println()
-	}
-
-	quadrantCountProduct := 1
-	for _, count := range quadrantCounts {
-		quadrantCountProduct *= count
+			style := robotCellStyle
+			style.Rune = '+'
+			if coordinateCount <= 9 {
+				style.Rune = rune('0' + coordinateCount)
+			}
+			return style
+		},
+	})
+	if err := renderRobots(countCanvas); err != nil {
+		return 0, err
 	}
 
-	return quadrantCountProduct, nil
+	return robot.SafetyFactor(positions, gridX, gridY), nil
 }
 
 func Part02(fileScanner *bufio.Scanner) (int, error) {
@@ -193,6 +251,75 @@ func Part02(fileScanner *bufio.Scanner) (int, error) {
 	robots := parseInputToRobots(fileScanner)
 	// slog.Debug("parsed input", "gridX", gridX, "gridY", gridY, "robots", robots)
 
+	if err := exportRobots(robots, gridX, gridY); err != nil {
+		return 0, err
+	}
+
+	if analyzeRobot >= 0 {
+		return reportRobotCycles(robots, gridX, gridY, analyzeRobot)
+	}
+
+	if interactive {
+		return 0, searchInteractively(robots, gridX, gridY)
+	}
+
+	var easterEggStep int
+	if easterEggMetric == "crt" {
+		step, err := robot.FindEasterEggCRT(robots, gridX, gridY)
+		if err != nil {
+			return 0, err
+		}
+		easterEggStep = step
+	} else {
+		metric, ok := easterEggMetrics[easterEggMetric]
+		if !ok {
+			return 0, fmt.Errorf("%w: %q", ErrorUnknownEasterEggMetric, easterEggMetric)
+		}
+		bestScore, err := robot.FindEasterEgg(robots, gridX, gridY, metric)
+		if err != nil {
+			return 0, err
+		}
+		easterEggStep = bestScore.Step
+	}
+
+	score := robot.ScoreFrame(robots, gridX, gridY, easterEggStep)
+	slog.Info("found easter egg",
+		"metric", easterEggMetric,
+		"step", easterEggStep,
+		"variance", score.Variance,
+		"entropy", score.Entropy,
+		"largest component", score.LargestComponent,
+		"safety factor", score.SafetyFactor,
+	)
+
+	robotInCoordinate := hashset.New[robot.Vector2]()
+	for _, position := range robot.ComputePositions(robots, gridX, gridY, easterEggStep) {
+		robotInCoordinate.Add(position)
+	}
+	fmt.Printf("Step Index: %v\n", easterEggStep)
+	if err := renderRobots(robotOccupancyCanvas(gridX, gridY, robotInCoordinate)); err != nil {
+		return 0, err
+	}
+
+	return easterEggStep, nil
+}
+
+// Get a canvas showing which cells of the grid contain a robot
+func robotOccupancyCanvas(gridX, gridY int, robotInCoordinate *hashset.HashSet[robot.Vector2]) *gridrender.Canvas {
+	return gridrender.NewCanvas(gridrender.FuncGrid{
+		GridWidth:  gridX,
+		GridHeight: gridY,
+		StyleFunc: func(coordinate gridutils.Coordinate) gridrender.CellStyle {
+			if robotInCoordinate.Contains(robot.Vector2{X: coordinate.X, Y: coordinate.Y}) {
+				return robotCellStyle
+			}
+			return emptyCellStyle
+		},
+	})
+}
+
+// Print every frame in which no two robots overlap, waiting for enter between frames so a human can spot the picture
+func searchInteractively(robots []*robot.Robot, gridX, gridY int) error {
 	keyboardScanner := bufio.NewScanner(os.Stdin)
 
 	for stepIndex := 0; stepIndex < 10000; stepIndex += 1 {
@@ -204,25 +331,16 @@ func Part02(fileScanner *bufio.Scanner) (int, error) {
 				toPrint = false
 			}
 			robotInCoordinate.Add(nextPosition)
-			// slog.Debug("robot stepped", "robot", *robot, "next position", nextPosition, "updated quadrant counts", quadrantCounts)
 		}
 
 		if toPrint {
 			fmt.Printf("\n\nStep Index: %v\n", stepIndex)
-			for y := 0; y < gridY; y += 1 {
-				for x := 0; x < gridX; x += 1 {
-					coordinate := robot.Vector2{X: x, Y: y}
-					if robotInCoordinate.Contains(coordinate) {
-						fmt.Print("#")
-					} else {
-						fmt.Print(".")
-					}
-				}
-				fmt.Println()
+			if err := renderRobots(robotOccupancyCanvas(gridX, gridY, robotInCoordinate)); err != nil {
+				return err
 			}
 			keyboardScanner.Scan()
 		}
 	}
 
-	return 0, nil
+	return nil
 }
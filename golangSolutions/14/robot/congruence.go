@@ -0,0 +1,74 @@
+package robot
+
+// Get the greatest common divisor of a and b, which is always non-negative
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return max(a, -a)
+}
+
+func lcm(a, b int) int {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return a / gcd(a, b) * b
+}
+
+// Get g = gcd(a, b) along with x and y such that ax + by = g
+func extendedGCD(a, b int) (int, int, int) {
+	oldR, r := a, b
+	oldX, x := 1, 0
+	oldY, y := 0, 1
+	for r != 0 {
+		quotient := oldR / r
+		oldR, r = r, oldR-quotient*r
+		oldX, x = x, oldX-quotient*x
+		oldY, y = y, oldY-quotient*y
+	}
+	if oldR < 0 {
+		return -oldR, -oldX, -oldY
+	}
+	return oldR, oldX, oldY
+}
+
+// Get the representative of a modulo m in [0, m)
+func mod(a, m int) int {
+	a %= m
+	if a < 0 {
+		a += m
+	}
+	return a
+}
+
+// Solve the pair of congruences t = a (mod m) and t = b (mod n), for positive moduli.
+//
+// Returns the smallest non-negative solution and the modulus lcm(m, n) of all solutions,
+// or false if the congruences are inconsistent.
+func solveCongruencePair(a, m, b, n int) (int, int, bool) {
+	g, x, _ := extendedGCD(m, n)
+	difference := b - a
+	if difference%g != 0 {
+		return 0, 0, false
+	}
+	combinedModulus := m / g * n
+
+	// t = a + m * k, where m * k = b - a (mod n), so k = x * (b - a) / g (mod n / g)
+	reducedModulus := n / g
+	k := mod(mod(x, reducedModulus)*mod(difference/g, reducedModulus), reducedModulus)
+	return mod(a+m*k, combinedModulus), combinedModulus, true
+}
+
+// Solve the linear congruence a * t = b (mod m), for positive modulus m.
+//
+// Returns the smallest non-negative solution and the modulus m / gcd(a, m) of all solutions,
+// or false if there is no solution.
+func solveLinearCongruence(a, b, m int) (int, int, bool) {
+	a, b = mod(a, m), mod(b, m)
+	g, x, _ := extendedGCD(a, m)
+	if b%g != 0 {
+		return 0, 0, false
+	}
+	reducedModulus := m / g
+	return mod(mod(x, reducedModulus)*(b/g), reducedModulus), reducedModulus, true
+}
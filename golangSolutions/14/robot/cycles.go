@@ -0,0 +1,129 @@
+package robot
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	ErrorNeverCollide     error = errors.New("robots never share a position")
+	ErrorNeverInRegion    error = errors.New("robot never enters region")
+	ErrorInvalidRegion    error = errors.New("region is empty or outside the grid")
+	ErrorQuadrantOutRange error = errors.New("quadrant must be between 0 and 3")
+)
+
+// A rectangle of the grid, including both corners
+type Region struct {
+	Min Vector2
+	Max Vector2
+}
+
+// Get one of the quadrants used for the safety factor, numbered 0 to 3 as top left, top right, bottom left, bottom right.
+// The middle row and column belong to no quadrant.
+func Quadrant(quadrant int, gridX, gridY int) (Region, error) {
+	if quadrant < 0 || quadrant > 3 {
+		return Region{}, fmt.Errorf("%w: %d", ErrorQuadrantOutRange, quadrant)
+	}
+	region := Region{Min: Vector2{0, 0}, Max: Vector2{gridX/2 - 1, gridY/2 - 1}}
+	if quadrant%2 == 1 {
+		region.Min.X, region.Max.X = gridX/2+1, gridX-1
+	}
+	if quadrant/2 == 1 {
+		region.Min.Y, region.Max.Y = gridY/2+1, gridY-1
+	}
+	return region, nil
+}
+
+// Two robots share a position at every step FirstStep + k * Period for non-negative k, and at no other step
+type Collision struct {
+	FirstStep int
+	Period    int
+}
+
+// Get the number of steps after which every robot is back at its initial position
+func GlobalPeriod(gridX, gridY int) int {
+	return lcm(gridX, gridY)
+}
+
+// Get the number of steps after which the robot returns to its initial position.
+// This divides the global period, and is shorter when a velocity component shares a factor with the grid size.
+func (robot *Robot) Period(gridX, gridY int) int {
+	xPeriod := gridX / gcd(mod(robot.velocity.X, gridX), gridX)
+	yPeriod := gridY / gcd(mod(robot.velocity.Y, gridY), gridY)
+	return lcm(xPeriod, yPeriod)
+}
+
+// Find every step at which two robots share a position, by solving the linear congruence
+// (velocity difference) * t = -(position difference) along each axis and combining the two.
+//
+// Returns ErrorNeverCollide if no such step exists.
+func (robot *Robot) CollisionWith(other *Robot, gridX, gridY int) (Collision, error) {
+	xStep, xModulus, xOk := solveLinearCongruence(
+		robot.velocity.X-other.velocity.X,
+		other.initialPosition.X-robot.initialPosition.X,
+		gridX,
+	)
+	yStep, yModulus, yOk := solveLinearCongruence(
+		robot.velocity.Y-other.velocity.Y,
+		other.initialPosition.Y-robot.initialPosition.Y,
+		gridY,
+	)
+	if !xOk || !yOk {
+		return Collision{}, ErrorNeverCollide
+	}
+
+	step, period, ok := solveCongruencePair(xStep, xModulus, yStep, yModulus)
+	if !ok {
+		return Collision{}, ErrorNeverCollide
+	}
+	return Collision{FirstStep: step, Period: period}, nil
+}
+
+// Get every residue class t = step (mod modulus) at which the robot coordinate along one axis lies in [low, high]
+func axisResidues(position, velocity, gridSize, low, high int) (steps []int, modulus int) {
+	for coordinate := low; coordinate <= high; coordinate += 1 {
+		step, stepModulus, ok := solveLinearCongruence(velocity, coordinate-position, gridSize)
+		if ok {
+			// Every solvable coordinate gives the same modulus gridSize / gcd(velocity, gridSize)
+			steps = append(steps, step)
+			modulus = stepModulus
+		}
+	}
+	return steps, modulus
+}
+
+// Find the first step, no earlier than fromStep, at which the robot is inside the region.
+//
+// The steps at which each coordinate lies in range are residue classes found from linear congruences,
+// and the robot is in the region exactly when both hold, so this never steps the robot through time.
+// Returns ErrorNeverInRegion if the robot is never in the region.
+func (robot *Robot) FirstStepInRegion(region Region, gridX, gridY int, fromStep int) (int, error) {
+	if region.Min.X > region.Max.X || region.Min.Y > region.Max.Y ||
+		region.Min.X < 0 || region.Min.Y < 0 || region.Max.X >= gridX || region.Max.Y >= gridY {
+		return 0, fmt.Errorf("%w: %v", ErrorInvalidRegion, region)
+	}
+
+	xSteps, xModulus := axisResidues(robot.initialPosition.X, robot.velocity.X, gridX, region.Min.X, region.Max.X)
+	ySteps, yModulus := axisResidues(robot.initialPosition.Y, robot.velocity.Y, gridY, region.Min.Y, region.Max.Y)
+
+	firstStep := math.MaxInt
+	for _, xStep := range xSteps {
+		for _, yStep := range ySteps {
+			step, period, ok := solveCongruencePair(xStep, xModulus, yStep, yModulus)
+			if !ok {
+				continue
+			}
+			// Advance to the first step of the residue class no earlier than fromStep
+			if step < fromStep {
+				step += (fromStep - step + period - 1) / period * period
+			}
+			firstStep = min(firstStep, step)
+		}
+	}
+
+	if firstStep == math.MaxInt {
+		return 0, ErrorNeverInRegion
+	}
+	return firstStep, nil
+}
@@ -0,0 +1,227 @@
+package robot
+
+import (
+	"errors"
+	"math"
+
+	arrayqueue "github.com/hmcalister/Go-DSA/queue/ArrayQueue"
+)
+
+//go:generate stringer --type FrameMetric
+type FrameMetric int
+
+const (
+	// The sum of the variances of the robot x and y coordinates. Low when robots cluster together.
+	FRAME_METRIC_VARIANCE FrameMetric = 0
+
+	// The Shannon entropy of the robot counts in blocks of the grid. Low when robots form a structured picture.
+	FRAME_METRIC_ENTROPY FrameMetric = 1
+
+	// The size of the largest orthogonally connected group of occupied cells. High when robots form a solid picture.
+	FRAME_METRIC_LARGEST_COMPONENT FrameMetric = 2
+
+	// The product of the robot counts in each quadrant, as in part 1. Low when robots crowd into one quadrant.
+	FRAME_METRIC_SAFETY_FACTOR FrameMetric = 3
+
+	// Side length of the square blocks the grid is split into when computing entropy
+	ENTROPY_BLOCK_SIZE int = 4
+)
+
+var (
+	ErrorNoRobots             error = errors.New("no robots to score")
+	ErrorUnknownFrameMetric   error = errors.New("unknown frame metric")
+	ErrorInconsistentMinima   error = errors.New("the x and y period minima do not share a step")
+	orthogonalNeighborOffsets       = []Vector2{{0, -1}, {1, 0}, {0, 1}, {-1, 0}}
+)
+
+// Every metric of the robot positions after a number of steps
+type FrameScore struct {
+	Step             int
+	Variance         float64
+	Entropy          float64
+	LargestComponent int
+	SafetyFactor     int
+}
+
+// Get the score of the frame under the given metric, where lower scores are more likely to be the picture
+func (score FrameScore) Value(metric FrameMetric) float64 {
+	switch metric {
+	case FRAME_METRIC_VARIANCE:
+		return score.Variance
+	case FRAME_METRIC_ENTROPY:
+		return score.Entropy
+	case FRAME_METRIC_LARGEST_COMPONENT:
+		return -float64(score.LargestComponent)
+	case FRAME_METRIC_SAFETY_FACTOR:
+		return float64(score.SafetyFactor)
+	default:
+		return math.Inf(1)
+	}
+}
+
+// Get the position of every robot after the given number of steps
+func ComputePositions(robots []*Robot, gridX, gridY int, numSteps int) []Vector2 {
+	positions := make([]Vector2, len(robots))
+	for robotIndex, robot := range robots {
+		positions[robotIndex] = robot.ComputePosition(gridX, gridY, numSteps)
+	}
+	return positions
+}
+
+// Get the product of the number of robots in each quadrant. Robots exactly on the middle row or column are not counted.
+func SafetyFactor(positions []Vector2, gridX, gridY int) int {
+	quadrantCounts := []int{0, 0, 0, 0}
+	for _, position := range positions {
+		if position.X < gridX/2 && position.Y < gridY/2 {
+			quadrantCounts[0] += 1
+		} else if position.X > gridX/2 && position.Y < gridY/2 {
+			quadrantCounts[1] += 1
+		} else if position.X < gridX/2 && position.Y > gridY/2 {
+			quadrantCounts[2] += 1
+		} else if position.X > gridX/2 && position.Y > gridY/2 {
+			quadrantCounts[3] += 1
+		}
+	}
+
+	safetyFactor := 1
+	for _, count := range quadrantCounts {
+		safetyFactor *= count
+	}
+	return safetyFactor
+}
+
+func variance(values []int) float64 {
+	mean := 0.0
+	for _, value := range values {
+		mean += float64(value)
+	}
+	mean /= float64(len(values))
+
+	sumSquaredDeviation := 0.0
+	for _, value := range values {
+		deviation := float64(value) - mean
+		sumSquaredDeviation += deviation * deviation
+	}
+	return sumSquaredDeviation / float64(len(values))
+}
+
+func blockEntropy(positions []Vector2) float64 {
+	blockCounts := make(map[Vector2]int)
+	for _, position := range positions {
+		blockCounts[Vector2{position.X / ENTROPY_BLOCK_SIZE, position.Y / ENTROPY_BLOCK_SIZE}] += 1
+	}
+
+	entropy := 0.0
+	for _, count := range blockCounts {
+		probability := float64(count) / float64(len(positions))
+		entropy -= probability * math.Log2(probability)
+	}
+	return entropy
+}
+
+func largestComponent(positions []Vector2) int {
+	unvisited := make(map[Vector2]bool, len(positions))
+	for _, position := range positions {
+		unvisited[position] = true
+	}
+
+	largest := 0
+	cellQueue := arrayqueue.New[Vector2]()
+	for _, position := range positions {
+		if !unvisited[position] {
+			continue
+		}
+		delete(unvisited, position)
+		cellQueue.Add(position)
+		componentSize := 0
+		for cellQueue.Size() > 0 {
+			cell, _ := cellQueue.Remove()
+			componentSize += 1
+			for _, offset := range orthogonalNeighborOffsets {
+				neighbor := Vector2{cell.X + offset.X, cell.Y + offset.Y}
+				if unvisited[neighbor] {
+					delete(unvisited, neighbor)
+					cellQueue.Add(neighbor)
+				}
+			}
+		}
+		largest = max(largest, componentSize)
+	}
+	return largest
+}
+
+// Compute every metric of the robot positions after the given number of steps
+func ScoreFrame(robots []*Robot, gridX, gridY int, numSteps int) FrameScore {
+	positions := ComputePositions(robots, gridX, gridY, numSteps)
+	xs := make([]int, len(positions))
+	ys := make([]int, len(positions))
+	for positionIndex, position := range positions {
+		xs[positionIndex] = position.X
+		ys[positionIndex] = position.Y
+	}
+
+	return FrameScore{
+		Step:             numSteps,
+		Variance:         variance(xs) + variance(ys),
+		Entropy:          blockEntropy(positions),
+		LargestComponent: largestComponent(positions),
+		SafetyFactor:     SafetyFactor(positions, gridX, gridY),
+	}
+}
+
+// Find the step at which the robots are most likely to form the picture, by scoring every frame in one full period
+// of the grid (after which all robots return to their starting positions) and taking the best under the metric.
+// Ties are broken by the earliest step.
+func FindEasterEgg(robots []*Robot, gridX, gridY int, metric FrameMetric) (FrameScore, error) {
+	if len(robots) == 0 {
+		return FrameScore{}, ErrorNoRobots
+	}
+	if metric < FRAME_METRIC_VARIANCE || metric > FRAME_METRIC_SAFETY_FACTOR {
+		return FrameScore{}, ErrorUnknownFrameMetric
+	}
+
+	bestScore := ScoreFrame(robots, gridX, gridY, 0)
+	for step := 1; step < lcm(gridX, gridY); step += 1 {
+		score := ScoreFrame(robots, gridX, gridY, step)
+		if score.Value(metric) < bestScore.Value(metric) {
+			bestScore = score
+		}
+	}
+	return bestScore, nil
+}
+
+// Find the step within the period of one axis that minimizes the variance of the robot coordinates along that axis
+func minimumVarianceStep(robots []*Robot, gridX, gridY int, period int, coordinate func(Vector2) int) int {
+	bestStep := 0
+	bestVariance := math.Inf(1)
+	values := make([]int, len(robots))
+	for step := range period {
+		for robotIndex, robot := range robots {
+			values[robotIndex] = coordinate(robot.ComputePosition(gridX, gridY, step))
+		}
+		if stepVariance := variance(values); stepVariance < bestVariance {
+			bestStep = step
+			bestVariance = stepVariance
+		}
+	}
+	return bestStep
+}
+
+// Find the step at which the robots are most likely to form the picture, scoring only gridX + gridY frames.
+//
+// The x coordinates of the robots repeat every gridX steps, and the y coordinates every gridY steps.
+// The picture clusters the robots along both axes, so the step minimizing the x variance within the x period
+// gives the picture step modulo gridX (and likewise for y). The Chinese Remainder Theorem combines the two.
+func FindEasterEggCRT(robots []*Robot, gridX, gridY int) (int, error) {
+	if len(robots) == 0 {
+		return 0, ErrorNoRobots
+	}
+	xStep := minimumVarianceStep(robots, gridX, gridY, gridX, func(position Vector2) int { return position.X })
+	yStep := minimumVarianceStep(robots, gridX, gridY, gridY, func(position Vector2) int { return position.Y })
+
+	step, _, ok := solveCongruencePair(xStep, gridX, yStep, gridY)
+	if !ok {
+		return 0, ErrorInconsistentMinima
+	}
+	return step, nil
+}
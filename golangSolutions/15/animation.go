@@ -0,0 +1,316 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"hmcalister/AdventOfCode/gridrender"
+	"hmcalister/AdventOfCode/gridutils"
+	"hmcalister/AdventOfCode/warehouse"
+	"image"
+	"image/color"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	ANIMATION_FORMAT_GIF  string = "gif"
+	ANIMATION_FORMAT_APNG string = "apng"
+	ANIMATION_FORMAT_NONE string = "none"
+
+	// The longest delay a single frame can hold, in hundredths of a second
+	MAX_FRAME_DELAY int = 1<<16 - 1
+)
+
+// Index of each theme color in the animation palette
+const (
+	PALETTE_INDEX_BACKGROUND uint8 = iota
+	PALETTE_INDEX_WALL
+	PALETTE_INDEX_BOX
+	PALETTE_INDEX_BOX_BORDER
+	PALETTE_INDEX_ROBOT
+)
+
+var (
+	ErrorUnknownAnimationFormat error = errors.New("unknown animation format")
+	ErrorUnknownTheme           error = errors.New("unknown animation theme")
+	ErrorInvalidPalette         error = errors.New("invalid animation palette")
+	ErrorInvalidAnimationConfig error = errors.New("invalid animation configuration")
+)
+
+// The colors used to draw each part of the warehouse
+type AnimationTheme struct {
+	Background color.RGBA
+	Wall       color.RGBA
+	Box        color.RGBA
+	BoxBorder  color.RGBA
+	Robot      color.RGBA
+}
+
+var animationThemes = map[string]AnimationTheme{
+	"light": {
+		Background: color.RGBA{255, 255, 255, 255},
+		Wall:       color.RGBA{0, 0, 0, 255},
+		Box:        color.RGBA{128, 128, 128, 255},
+		BoxBorder:  color.RGBA{96, 96, 96, 255},
+		Robot:      color.RGBA{128, 196, 128, 255},
+	},
+	"dark": {
+		Background: color.RGBA{24, 24, 32, 255},
+		Wall:       color.RGBA{120, 120, 140, 255},
+		Box:        color.RGBA{200, 160, 90, 255},
+		BoxBorder:  color.RGBA{140, 100, 50, 255},
+		Robot:      color.RGBA{90, 220, 120, 255},
+	},
+	"contrast": {
+		Background: color.RGBA{0, 0, 0, 255},
+		Wall:       color.RGBA{255, 255, 255, 255},
+		Box:        color.RGBA{255, 220, 0, 255},
+		BoxBorder:  color.RGBA{255, 0, 0, 255},
+		Robot:      color.RGBA{0, 200, 255, 255},
+	},
+}
+
+// Get the named theme, or parse a comma separated list of five hex colors (background, wall, box, box border, robot)
+func parseAnimationTheme(themeStr string) (AnimationTheme, error) {
+	if theme, ok := animationThemes[themeStr]; ok {
+		return theme, nil
+	}
+	if !strings.Contains(themeStr, ",") {
+		return AnimationTheme{}, fmt.Errorf("%w: %q", ErrorUnknownTheme, themeStr)
+	}
+
+	colorStrs := strings.Split(themeStr, ",")
+	if len(colorStrs) != 5 {
+		return AnimationTheme{}, fmt.Errorf("%w: expected 5 colors, found %d", ErrorInvalidPalette, len(colorStrs))
+	}
+	colors := make([]color.RGBA, len(colorStrs))
+	for colorIndex, colorStr := range colorStrs {
+		colorStr = strings.TrimPrefix(strings.TrimSpace(colorStr), "#")
+		colorValue, err := strconv.ParseUint(colorStr, 16, 32)
+		if err != nil || len(colorStr) != 6 {
+			return AnimationTheme{}, fmt.Errorf("%w: %q is not a hex color", ErrorInvalidPalette, colorStrs[colorIndex])
+		}
+		colors[colorIndex] = color.RGBA{uint8(colorValue >> 16), uint8(colorValue >> 8), uint8(colorValue), 255}
+	}
+	return AnimationTheme{colors[0], colors[1], colors[2], colors[3], colors[4]}, nil
+}
+
+func (theme AnimationTheme) palette() color.Palette {
+	return color.Palette{theme.Background, theme.Wall, theme.Box, theme.BoxBorder, theme.Robot}
+}
+
+// How the robot's moves are rendered
+type AnimationConfig struct {
+	// One of ANIMATION_FORMAT_GIF, ANIMATION_FORMAT_APNG, or ANIMATION_FORMAT_NONE
+	Format         string
+	OutputFilePath string
+	Theme          AnimationTheme
+
+	// Side length of each warehouse cell in pixels, and the width of the border drawn around boxes
+	CellSize    int
+	BorderWidth int
+
+	FramesPerSecond int
+
+	// Render only every FrameStride-th move. The final move is always rendered.
+	FrameStride int
+
+	// By default a frame where nothing moved extends the previous frame rather than being written again
+	KeepUnchangedFrames bool
+}
+
+// The file extension matching the animation format
+func (config AnimationConfig) FileExtension() string {
+	if config.Format == ANIMATION_FORMAT_APNG {
+		return "png"
+	}
+	return config.Format
+}
+
+// ------------------------------------------------------------------------------------------------------------------------
+
+// Anything that can encode frames one at a time
+type frameWriter interface {
+	WriteFrame(frame *image.Paletted, delay int) error
+	Close() error
+}
+
+// Renders the warehouse after each robot step straight into an animation file, so only a couple of frames are held in
+// memory no matter how many steps are taken.
+//
+// The most recent frame is held back until the next differing frame arrives, so that frames where nothing moved
+// are merged into it by lengthening its delay.
+type WarehouseAnimator struct {
+	config      AnimationConfig
+	outputFile  *os.File
+	writer      frameWriter
+	frameDelay  int
+	frameBounds image.Rectangle
+
+	// The frame yet to be written, and a spare frame to render into
+	pendingFrame *image.Paletted
+	pendingDelay int
+	spareFrame   *image.Paletted
+
+	// Steps since the last rendered frame, and whether anything moved during them
+	numStepsSinceFrame int
+	changedSinceFrame  bool
+
+	NumFramesWritten int
+	NumFramesMerged  int
+}
+
+// Create the animation file and render the warehouse as it currently stands as the first frame
+func NewWarehouseAnimator(config AnimationConfig, warehouseMap *warehouse.Warehouse) (*WarehouseAnimator, error) {
+	if config.CellSize <= 0 || config.BorderWidth < 0 || 2*config.BorderWidth >= config.CellSize {
+		return nil, fmt.Errorf("%w: cell size %d with border width %d", ErrorInvalidAnimationConfig, config.CellSize, config.BorderWidth)
+	}
+	if config.FramesPerSecond <= 0 || config.FramesPerSecond > gridrender.GIF_DELAY_UNITS_PER_SECOND {
+		return nil, fmt.Errorf("%w: frames per second must be between 1 and %d", ErrorInvalidAnimationConfig, gridrender.GIF_DELAY_UNITS_PER_SECOND)
+	}
+	if config.FrameStride <= 0 {
+		return nil, fmt.Errorf("%w: frame stride must be positive", ErrorInvalidAnimationConfig)
+	}
+
+	animator := &WarehouseAnimator{
+		config:      config,
+		frameDelay:  gridrender.GIF_DELAY_UNITS_PER_SECOND / config.FramesPerSecond,
+		frameBounds: image.Rect(0, 0, config.CellSize*warehouseMap.Width(), config.CellSize*warehouseMap.Height()),
+	}
+
+	outputFile, err := os.Create(config.OutputFilePath)
+	if err != nil {
+		return nil, err
+	}
+	animator.outputFile = outputFile
+	width, height := animator.frameBounds.Dx(), animator.frameBounds.Dy()
+	switch config.Format {
+	case ANIMATION_FORMAT_GIF:
+		animator.writer, err = gridrender.NewGIFWriter(outputFile, width, height, config.Theme.palette())
+	case ANIMATION_FORMAT_APNG:
+		animator.writer = gridrender.NewAPNGWriter(outputFile, width, height)
+	default:
+		err = fmt.Errorf("%w: %q", ErrorUnknownAnimationFormat, config.Format)
+	}
+	if err != nil {
+		outputFile.Close()
+		return nil, err
+	}
+
+	animator.pendingFrame = image.NewPaletted(animator.frameBounds, config.Theme.palette())
+	animator.spareFrame = image.NewPaletted(animator.frameBounds, config.Theme.palette())
+	animator.renderWarehouse(animator.pendingFrame, warehouseMap)
+	animator.pendingDelay = animator.frameDelay
+	return animator, nil
+}
+
+// Record a robot step that has just been applied to the warehouse, rendering a frame if one is due
+func (animator *WarehouseAnimator) Step(warehouseMap *warehouse.Warehouse, entry warehouse.JournalEntry) error {
+	animator.numStepsSinceFrame += 1
+	animator.changedSinceFrame = animator.changedSinceFrame || !entry.RobotFrom.Equal(entry.RobotTo)
+	if animator.numStepsSinceFrame < animator.config.FrameStride {
+		return nil
+	}
+	return animator.renderFrame(warehouseMap)
+}
+
+func (animator *WarehouseAnimator) renderFrame(warehouseMap *warehouse.Warehouse) error {
+	changed := animator.changedSinceFrame
+	animator.numStepsSinceFrame = 0
+	animator.changedSinceFrame = false
+
+	if !changed && !animator.config.KeepUnchangedFrames && animator.pendingDelay+animator.frameDelay <= MAX_FRAME_DELAY {
+		animator.pendingDelay += animator.frameDelay
+		animator.NumFramesMerged += 1
+		return nil
+	}
+
+	animator.renderWarehouse(animator.spareFrame, warehouseMap)
+	if err := animator.writer.WriteFrame(animator.pendingFrame, animator.pendingDelay); err != nil {
+		return err
+	}
+	animator.NumFramesWritten += 1
+	animator.pendingFrame, animator.spareFrame = animator.spareFrame, animator.pendingFrame
+	animator.pendingDelay = animator.frameDelay
+	return nil
+}
+
+// Render any remaining steps, write the last frame, and close the animation file
+func (animator *WarehouseAnimator) Close(warehouseMap *warehouse.Warehouse) error {
+	defer animator.outputFile.Close()
+	if animator.numStepsSinceFrame > 0 {
+		if err := animator.renderFrame(warehouseMap); err != nil {
+			return err
+		}
+	}
+	if err := animator.writer.WriteFrame(animator.pendingFrame, animator.pendingDelay); err != nil {
+		return err
+	}
+	animator.NumFramesWritten += 1
+	if err := animator.writer.Close(); err != nil {
+		return err
+	}
+	return animator.outputFile.Close()
+}
+
+// ------------------------------------------------------------------------------------------------------------------------
+
+// Set every pixel of the rectangle (clipped to the frame) to the given palette index
+func fillRect(frame *image.Paletted, rect image.Rectangle, paletteIndex uint8) {
+	rect = rect.Intersect(frame.Bounds())
+	for y := rect.Min.Y; y < rect.Max.Y; y += 1 {
+		rowStart := frame.PixOffset(rect.Min.X, y)
+		row := frame.Pix[rowStart : rowStart+rect.Dx()]
+		for x := range row {
+			row[x] = paletteIndex
+		}
+	}
+}
+
+// Draw the warehouse into the frame. Boxes get a border around their outline only, so multi-cell boxes are drawn as one shape.
+func (animator *WarehouseAnimator) renderWarehouse(frame *image.Paletted, warehouseMap *warehouse.Warehouse) {
+	cellSize := animator.config.CellSize
+	borderWidth := animator.config.BorderWidth
+	fillRect(frame, frame.Bounds(), PALETTE_INDEX_BACKGROUND)
+
+	for y := range warehouseMap.Height() {
+		for x := range warehouseMap.Width() {
+			coord := gridutils.Coordinate{X: x, Y: y}
+			cellRect := image.Rect(cellSize*x, cellSize*y, cellSize*(x+1), cellSize*(y+1))
+			if warehouseMap.IsWall(coord) {
+				fillRect(frame, cellRect, PALETTE_INDEX_WALL)
+				continue
+			}
+			boxIndex, isBox := warehouseMap.BoxAt(coord)
+			if !isBox {
+				continue
+			}
+
+			// Inset the box color from each edge, unless the neighboring cell belongs to the same box
+			fillRect(frame, cellRect, PALETTE_INDEX_BOX_BORDER)
+			innerRect := cellRect.Inset(borderWidth)
+			sameBox := func(direction gridutils.Direction) bool {
+				neighborIndex, ok := warehouseMap.BoxAt(coord.Step(direction))
+				return ok && neighborIndex == boxIndex
+			}
+			if sameBox(gridutils.DIRECTION_LEFT) {
+				innerRect.Min.X = cellRect.Min.X
+			}
+			if sameBox(gridutils.DIRECTION_RIGHT) {
+				innerRect.Max.X = cellRect.Max.X
+			}
+			if sameBox(gridutils.DIRECTION_UP) {
+				innerRect.Min.Y = cellRect.Min.Y
+			}
+			if sameBox(gridutils.DIRECTION_DOWN) {
+				innerRect.Max.Y = cellRect.Max.Y
+			}
+			fillRect(frame, innerRect, PALETTE_INDEX_BOX)
+		}
+	}
+
+	robotPosition := warehouseMap.RobotPosition()
+	robotRect := image.Rect(cellSize*robotPosition.X, cellSize*robotPosition.Y, cellSize*(robotPosition.X+1), cellSize*(robotPosition.Y+1))
+	fillRect(frame, robotRect, PALETTE_INDEX_ROBOT)
+}
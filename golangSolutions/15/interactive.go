@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"hmcalister/AdventOfCode/gridrender"
+	"hmcalister/AdventOfCode/gridutils"
+	"hmcalister/AdventOfCode/warehouse"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const (
+	INTERACTIVE_HELP string = "arrows/wasd: move robot   u: undo   r: redo   [: first step   ]: last step   q: quit"
+
+	// Escape sequences for clearing the terminal and moving the cursor to the top left
+	CLEAR_SCREEN_SEQUENCE string = "\033[H\033[2J"
+)
+
+var (
+	// Styles for each rune of the warehouse string. Any other rune is a box cell.
+	warehouseRuneStyles = map[rune]gridrender.CellStyle{
+		warehouse.WALL_RUNE:  {Rune: warehouse.WALL_RUNE, ANSIStyle: "90", Color: animationThemes["light"].Wall},
+		warehouse.ROBOT_RUNE: {Rune: warehouse.ROBOT_RUNE, ANSIStyle: "1;32", Color: animationThemes["light"].Robot},
+		warehouse.EMPTY_RUNE: {Rune: warehouse.EMPTY_RUNE, ANSIStyle: "2", Color: animationThemes["light"].Background},
+	}
+	warehouseBoxStyle = gridrender.CellStyle{ANSIStyle: "33", Color: animationThemes["light"].Box}
+)
+
+// Get a canvas of the warehouse, keeping the runes of the warehouse string
+func warehouseCanvas(warehouseMap *warehouse.Warehouse) *gridrender.Canvas {
+	warehouseLines := strings.Split(warehouseMap.String(), "\n")
+	return gridrender.NewCanvas(gridrender.FuncGrid{
+		GridWidth:  warehouseMap.Width(),
+		GridHeight: warehouseMap.Height(),
+		StyleFunc: func(coordinate gridutils.Coordinate) gridrender.CellStyle {
+			cellRune := rune(warehouseLines[coordinate.Y][coordinate.X])
+			if style, ok := warehouseRuneStyles[cellRune]; ok {
+				return style
+			}
+			style := warehouseBoxStyle
+			style.Rune = cellRune
+			return style
+		},
+	})
+}
+
+// Switch the terminal between reading single key presses without echo, and the usual line-by-line input.
+// There is no terminal handling in the standard library, so this shells out to stty.
+func setTerminalKeyMode(keyMode bool) error {
+	sttyArgs := []string{"echo", "icanon"}
+	if keyMode {
+		sttyArgs = []string{"-echo", "-icanon", "min", "1"}
+	}
+	sttyCommand := exec.Command("stty", sttyArgs...)
+	sttyCommand.Stdin = os.Stdin
+	return sttyCommand.Run()
+}
+
+// Read a single key press, translating arrow key escape sequences into the matching wasd key
+func readKey(keyReader *bufio.Reader) (byte, error) {
+	key, err := keyReader.ReadByte()
+	if err != nil || key != '\033' {
+		return key, err
+	}
+
+	// Arrow keys are sent as ESC [ A/B/C/D
+	if next, err := keyReader.ReadByte(); err != nil || next != '[' {
+		return key, err
+	}
+	arrowKey, err := keyReader.ReadByte()
+	if err != nil {
+		return key, err
+	}
+	switch arrowKey {
+	case 'A':
+		return 'w', nil
+	case 'B':
+		return 's', nil
+	case 'C':
+		return 'd', nil
+	case 'D':
+		return 'a', nil
+	}
+	return key, nil
+}
+
+// Drive the robot around the warehouse from the keyboard until the user quits, returning the GPS total at that point.
+//
+// The given moves are applied then undone, so they can be replayed one at a time with redo
+// (until the robot is moved by hand, which discards them).
+func runInteractiveWarehouse(warehouseMap *warehouse.Warehouse, robotStepDirections []gridutils.Direction) (int, error) {
+	for _, robotStepDirection := range robotStepDirections {
+		warehouseMap.RobotStep(robotStepDirection)
+	}
+	warehouseMap.SeekToStep(0)
+
+	if err := setTerminalKeyMode(true); err != nil {
+		return 0, fmt.Errorf("could not set terminal mode: %w", err)
+	}
+	defer setTerminalKeyMode(false)
+
+	keyReader := bufio.NewReader(os.Stdin)
+	keyDirections := map[byte]gridutils.Direction{
+		'w': gridutils.DIRECTION_UP,
+		'd': gridutils.DIRECTION_RIGHT,
+		's': gridutils.DIRECTION_DOWN,
+		'a': gridutils.DIRECTION_LEFT,
+	}
+	for {
+		journal := warehouseMap.Journal()
+		fmt.Print(CLEAR_SCREEN_SEQUENCE)
+		fmt.Print(gridrender.RenderANSI(warehouseCanvas(warehouseMap)))
+		fmt.Printf("step %d/%d   GPS %d\n%s\n", journal.CurrentStep(), journal.NumSteps(), warehouseMap.ComputeGPS(), INTERACTIVE_HELP)
+
+		key, err := readKey(keyReader)
+		if err != nil {
+			return warehouseMap.ComputeGPS(), err
+		}
+		if direction, ok := keyDirections[key]; ok {
+			warehouseMap.RobotStep(direction)
+			continue
+		}
+		switch key {
+		case 'u':
+			warehouseMap.Undo()
+		case 'r':
+			warehouseMap.Redo()
+		case '[':
+			warehouseMap.SeekToStep(0)
+		case ']':
+			warehouseMap.SeekToStep(journal.NumSteps())
+		case 'q':
+			return warehouseMap.ComputeGPS(), nil
+		}
+	}
+}
@@ -7,14 +7,9 @@ import (
 	"fmt"
 	"hmcalister/AdventOfCode/gridutils"
 	"hmcalister/AdventOfCode/warehouse"
-	"image"
-	"image/color"
-	"image/draw"
-	"image/gif"
 	"log/slog"
 	"os"
 	"runtime/pprof"
-	"strings"
 	"time"
 )
 
@@ -22,11 +17,44 @@ const (
 	CPU_PROFILE_FILEPATH string = "profile"
 )
 
+var (
+	interactiveMode bool
+	warehouseScale  int
+
+	animationFormat     string
+	animationFilePath   string
+	animationTheme      string
+	animationCellSize   int
+	animationFPS        int
+	animationStride     int
+	keepUnchangedFrames bool
+
+	findFrozenBoxes bool
+	planBoxCell     string
+	planTarget      string
+	planStrategy    int
+	planMaxStates   int
+)
+
 func main() {
 	debugFlag := flag.Bool("debug", false, "Debug Flag")
 	inputFilePath := flag.String("inputFile", "puzzleInput", "Path to input file.")
 	selectedPart := flag.Int("part", 0, "Part to execute. Must be 1 or 2.")
 	profile := flag.Bool("profile", false, "Flag to profile program")
+	flag.BoolVar(&interactiveMode, "interactive", false, "Drive the robot from the keyboard over the selected part's warehouse, with the input moves available to redo.")
+	flag.IntVar(&warehouseScale, "scale", 0, "Horizontal scale of the warehouse, i.e. the width of each box. Defaults to 1 for part 1 and 2 for part 2.")
+	flag.StringVar(&animationFormat, "animationFormat", ANIMATION_FORMAT_GIF, "Format of the animation of the robot's moves. One of gif, apng, or none.")
+	flag.StringVar(&animationFilePath, "animationFile", "", "Path to write the animation to. Defaults to part01 or part02, with the extension of the format.")
+	flag.StringVar(&animationTheme, "theme", "light", "Animation theme, one of light, dark, or contrast, or five comma separated hex colors for the background, walls, boxes, box borders, and robot.")
+	flag.IntVar(&animationCellSize, "cellSize", 32, "Side length in pixels of each warehouse cell in the animation.")
+	flag.IntVar(&animationFPS, "fps", 12, "Frame rate of the animation.")
+	flag.IntVar(&animationStride, "frameStride", 1, "Render a frame only every this many moves.")
+	flag.BoolVar(&keepUnchangedFrames, "keepUnchangedFrames", false, "Write a frame for every rendered move, even if nothing moved since the last frame.")
+	flag.BoolVar(&findFrozenBoxes, "findFrozenBoxes", false, "Report the boxes of the initial warehouse that can never move again, instead of replaying the moves.")
+	flag.StringVar(&planBoxCell, "planBox", "", "Cell x,y of a box in the initial warehouse to plan pushing to -planTarget, instead of replaying the moves.")
+	flag.StringVar(&planTarget, "planTarget", "", "Cell x,y to push the top left of the -planBox box to.")
+	flag.IntVar(&planStrategy, "planStrategy", int(warehouse.PLAN_STRATEGY_ASTAR), "Search strategy for -planBox. 0 for BFS, 1 for A*.")
+	flag.IntVar(&planMaxStates, "planMaxStates", warehouse.DEFAULT_PLAN_MAX_STATES, "Maximum number of warehouse states -planBox may expand.")
 	flag.Parse()
 	if *profile {
 		f, err := os.Create(CPU_PROFILE_FILEPATH)
@@ -74,7 +102,8 @@ func main() {
 	slog.Info("computation completed", "result", result, "computation time elapsed (ns)", computationEndTime.Sub(computationStartTime).Nanoseconds())
 }
 
-func Part01(fileScanner *bufio.Scanner) (int, error) {
+// Read the warehouse map lines, up to the first blank line, followed by the robot moves
+func parseWarehouseInput(fileScanner *bufio.Scanner) ([]string, []gridutils.Direction, error) {
 	warehouseMapStrs := make([]string, 0)
 	for fileScanner.Scan() {
 		line := fileScanner.Text()
@@ -83,14 +112,12 @@ func Part01(fileScanner *bufio.Scanner) (int, error) {
 		}
 		warehouseMapStrs = append(warehouseMapStrs, line)
 	}
-	warehouseMap := warehouse.NewSingleWidthWarehouseMap(warehouseMapStrs)
-	fmt.Println(warehouseMap)
 
-	frames := make([]string, 0)
-	var robotStepDirection gridutils.Direction
+	robotStepDirections := make([]gridutils.Direction, 0)
 	for fileScanner.Scan() {
 		line := fileScanner.Text()
 		for _, robotStepDirectionRune := range line {
+			var robotStepDirection gridutils.Direction
 			switch robotStepDirectionRune {
 			case '^':
 				robotStepDirection = gridutils.DIRECTION_UP
@@ -102,144 +129,117 @@ func Part01(fileScanner *bufio.Scanner) (int, error) {
 				robotStepDirection = gridutils.DIRECTION_LEFT
 			default:
 				slog.Error("unexpected robot direction encountered", "rune found", robotStepDirectionRune)
-				return 0, errors.New("could not parse robot direction input")
+				return nil, nil, errors.New("could not parse robot direction input")
 			}
-			slog.Debug("robot moving", "rune found", robotStepDirectionRune, "robot direction", robotStepDirection)
-			warehouseMap.RobotStep(robotStepDirection)
-			frames = append(frames, warehouseMap.String())
+			robotStepDirections = append(robotStepDirections, robotStepDirection)
 		}
 	}
 
-	createGIF(frames, "part01.gif", 12)
-	return warehouseMap.ComputeGPS(), nil
+	return warehouseMapStrs, robotStepDirections, nil
 }
 
-func Part02(fileScanner *bufio.Scanner) (int, error) {
-	warehouseMapStrs := make([]string, 0)
-	for fileScanner.Scan() {
-		line := fileScanner.Text()
-		if len(line) == 0 {
-			break
-		}
-		warehouseMapStrs = append(warehouseMapStrs, line)
+func Part01(fileScanner *bufio.Scanner) (int, error) {
+	scale := 1
+	warehouseMapStrs, robotStepDirections, err := parseWarehouseInput(fileScanner)
+	if err != nil {
+		return 0, err
+	}
+	if warehouseScale > 0 {
+		scale = warehouseScale
+	}
+	warehouseMap, err := warehouse.NewWarehouse(warehouseMapStrs, scale)
+	if err != nil {
+		return 0, err
+	}
+	if interactiveMode {
+		return runInteractiveWarehouse(warehouseMap, robotStepDirections)
+	}
+	if findFrozenBoxes {
+		return reportFrozenBoxes(warehouseMap), nil
+	}
+	if planBoxCell != "" {
+		return reportBoxPushPlan(warehouseMap, planBoxCell, planTarget)
 	}
-	warehouseMap := warehouse.NewDoubleWidthWarehouseMap(warehouseMapStrs)
 	fmt.Println(warehouseMap)
 
-	frames := make([]string, 0)
-	var robotStepDirection gridutils.Direction
-	for fileScanner.Scan() {
-		line := fileScanner.Text()
-		for _, robotStepDirectionRune := range line {
-			switch robotStepDirectionRune {
-			case '^':
-				robotStepDirection = gridutils.DIRECTION_UP
-			case '>':
-				robotStepDirection = gridutils.DIRECTION_RIGHT
-			case 'v':
-				robotStepDirection = gridutils.DIRECTION_DOWN
-			case '<':
-				robotStepDirection = gridutils.DIRECTION_LEFT
-			default:
-				slog.Error("unexpected robot direction encountered", "rune found", robotStepDirectionRune)
-				return 0, errors.New("could not parse robot direction input")
-			}
-			slog.Debug("robot moving", "rune found", robotStepDirectionRune, "robot direction", robotStepDirection)
-			warehouseMap.RobotStep(robotStepDirection)
-			frames = append(frames, warehouseMap.String())
-		}
+	if err := runRobotSteps(warehouseMap, robotStepDirections, "part01"); err != nil {
+		return 0, err
 	}
-
-	createGIF(frames, "part02.gif", 12)
 	return warehouseMap.ComputeGPS(), nil
 }
 
-// Turn the warehouse map string into an image
-func stringToImage(s string) *image.Paletted {
-	cellSize := 32
-	borderWidth := 4
-	backgroundColor := color.RGBA{255, 255, 255, 255}
-	wallColor := color.RGBA{0, 0, 0, 255}
-	boxBorderColor := color.RGBA{96, 96, 96, 255}
-	boxColor := color.RGBA{128, 128, 128, 255}
-	robotColor := color.RGBA{128, 196, 128, 255}
-
-	lines := strings.Split(s, "\n")
-
-	img := image.NewRGBA(
-		image.Rect(
-			0,
-			0,
-			cellSize*len(lines[0]),
-			cellSize*(len(lines)-1),
-		),
-	)
-	draw.Draw(img, img.Bounds(), &image.Uniform{backgroundColor}, image.Point{}, draw.Src)
-
-	for y, line := range lines {
-		for x, cell := range line {
-			var currentCellColor color.RGBA
-			switch cell {
-			case warehouse.ROBOT_RUNE:
-				currentCellColor = robotColor
-			case warehouse.WALL_RUNE:
-				currentCellColor = wallColor
-			case warehouse.BOX_RUNE:
-				currentCellColor = boxColor
-			case '[':
-				// DUAL BOX!!!
-				currentCellColor = boxColor
-				borderRect := image.Rect(
-					cellSize*x,
-					cellSize*y,
-					cellSize*(x+2),
-					cellSize*(y+1),
-				)
-				draw.Draw(img, borderRect, &image.Uniform{boxBorderColor}, image.Point{}, draw.Src)
-				cellRect := image.Rect(
-					cellSize*x+borderWidth,
-					cellSize*y+borderWidth,
-					cellSize*(x+2)-borderWidth,
-					cellSize*(y+1)-borderWidth,
-				)
-				draw.Draw(img, cellRect, &image.Uniform{boxColor}, image.Point{}, draw.Src)
-				continue
-			case ']':
-				continue
-			default:
-				currentCellColor = backgroundColor
-			}
-			cellRect := image.Rect(cellSize*x, cellSize*y, cellSize*(x+1), cellSize*(y+1))
-			draw.Draw(img, cellRect, &image.Uniform{currentCellColor}, image.Point{}, draw.Src)
-		}
+func Part02(fileScanner *bufio.Scanner) (int, error) {
+	scale := 2
+	warehouseMapStrs, robotStepDirections, err := parseWarehouseInput(fileScanner)
+	if err != nil {
+		return 0, err
 	}
+	if warehouseScale > 0 {
+		scale = warehouseScale
+	}
+	warehouseMap, err := warehouse.NewWarehouse(warehouseMapStrs, scale)
+	if err != nil {
+		return 0, err
+	}
+	if interactiveMode {
+		return runInteractiveWarehouse(warehouseMap, robotStepDirections)
+	}
+	if findFrozenBoxes {
+		return reportFrozenBoxes(warehouseMap), nil
+	}
+	if planBoxCell != "" {
+		return reportBoxPushPlan(warehouseMap, planBoxCell, planTarget)
+	}
+	fmt.Println(warehouseMap)
 
-	pal := []color.Color{backgroundColor, wallColor, boxColor, boxBorderColor, robotColor}
-	palettedImg := image.NewPaletted(img.Bounds(), pal)
-	draw.FloydSteinberg.Draw(palettedImg, img.Bounds(), img, image.Point{})
-
-	return palettedImg
+	if err := runRobotSteps(warehouseMap, robotStepDirections, "part02"); err != nil {
+		return 0, err
+	}
+	return warehouseMap.ComputeGPS(), nil
 }
 
-func createGIF(frameStrings []string, outputFilePath string, delay int) error {
-	frames := make([]*image.Paletted, 0)
-	delays := make([]int, 0)
-
-	for frameIndex, frameString := range frameStrings {
-		slog.Debug("converting frame", "frame index", frameIndex, "total frames", len(frameStrings))
-		img := stringToImage(frameString)
-		frames = append(frames, img)
-		delays = append(delays, delay)
+// Apply each robot step to the warehouse, streaming an animation of the moves to file unless animations are disabled
+func runRobotSteps(warehouseMap *warehouse.Warehouse, robotStepDirections []gridutils.Direction, defaultFileStem string) error {
+	if animationFormat == ANIMATION_FORMAT_NONE {
+		for _, robotStepDirection := range robotStepDirections {
+			warehouseMap.RobotStep(robotStepDirection)
+		}
+		return nil
 	}
 
-	f, err := os.Create(outputFilePath)
+	theme, err := parseAnimationTheme(animationTheme)
+	if err != nil {
+		return err
+	}
+	config := AnimationConfig{
+		Format:              animationFormat,
+		OutputFilePath:      animationFilePath,
+		Theme:               theme,
+		CellSize:            animationCellSize,
+		BorderWidth:         animationCellSize / 8,
+		FramesPerSecond:     animationFPS,
+		FrameStride:         animationStride,
+		KeepUnchangedFrames: keepUnchangedFrames,
+	}
+	if config.OutputFilePath == "" {
+		config.OutputFilePath = defaultFileStem + "." + config.FileExtension()
+	}
+	animator, err := NewWarehouseAnimator(config, warehouseMap)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
 
-	return gif.EncodeAll(f, &gif.GIF{
-		Image: frames,
-		Delay: delays,
-	})
+	for _, robotStepDirection := range robotStepDirections {
+		slog.Debug("robot moving", "robot direction", robotStepDirection)
+		warehouseMap.RobotStep(robotStepDirection)
+		journal := warehouseMap.Journal()
+		if err := animator.Step(warehouseMap, journal.Entries()[journal.CurrentStep()-1]); err != nil {
+			return err
+		}
+	}
+	if err := animator.Close(warehouseMap); err != nil {
+		return err
+	}
+	slog.Info("animation written", "file", config.OutputFilePath, "frames written", animator.NumFramesWritten, "frames merged", animator.NumFramesMerged)
+	return nil
 }
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"hmcalister/AdventOfCode/gridrender"
+	"hmcalister/AdventOfCode/gridutils"
+	"hmcalister/AdventOfCode/warehouse"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrorInvalidCoordinate error = errors.New("coordinate must be given as x,y")
+
+	// Frozen box cells are drawn over the warehouse in this style
+	frozenBoxStyle = gridrender.CellStyle{Rune: 'X', ANSIStyle: "1;31", Color: animationThemes["contrast"].BoxBorder}
+
+	directionRunes = map[gridutils.Direction]rune{
+		gridutils.DIRECTION_UP:    '^',
+		gridutils.DIRECTION_RIGHT: '>',
+		gridutils.DIRECTION_DOWN:  'v',
+		gridutils.DIRECTION_LEFT:  '<',
+	}
+)
+
+func parseCoordinate(coordinateStr string) (gridutils.Coordinate, error) {
+	coordinateStrs := strings.Split(coordinateStr, ",")
+	if len(coordinateStrs) != 2 {
+		return gridutils.Coordinate{}, fmt.Errorf("%w: %q", ErrorInvalidCoordinate, coordinateStr)
+	}
+	x, xErr := strconv.Atoi(strings.TrimSpace(coordinateStrs[0]))
+	y, yErr := strconv.Atoi(strings.TrimSpace(coordinateStrs[1]))
+	if errors.Join(xErr, yErr) != nil {
+		return gridutils.Coordinate{}, fmt.Errorf("%w: %q", ErrorInvalidCoordinate, coordinateStr)
+	}
+	return gridutils.Coordinate{X: x, Y: y}, nil
+}
+
+// Print the warehouse with every frozen box marked, returning the number of frozen boxes
+func reportFrozenBoxes(warehouseMap *warehouse.Warehouse) int {
+	frozenBoxes := warehouseMap.FrozenBoxes()
+	frozenOverlay := gridrender.EntityOverlay{}
+	for _, boxIndex := range frozenBoxes {
+		box := warehouseMap.Boxes()[boxIndex]
+		for _, cell := range box.Cells {
+			frozenOverlay.Add(cell, frozenBoxStyle)
+		}
+		slog.Info("found frozen box", "box index", boxIndex, "top left", box.TopLeft())
+	}
+
+	canvas := warehouseCanvas(warehouseMap)
+	frozenOverlay.Apply(canvas)
+	fmt.Print(gridrender.RenderASCII(canvas))
+	fmt.Printf("%d of %d boxes can never move again\n", len(frozenBoxes), len(warehouseMap.Boxes()))
+	return len(frozenBoxes)
+}
+
+// Plan the shortest sequence of moves pushing the box in the given cell to the target, then print the moves
+// and the warehouse after making them. Returns the number of moves.
+func reportBoxPushPlan(warehouseMap *warehouse.Warehouse, boxCellStr string, targetStr string) (int, error) {
+	boxCell, err := parseCoordinate(boxCellStr)
+	if err != nil {
+		return 0, err
+	}
+	target, err := parseCoordinate(targetStr)
+	if err != nil {
+		return 0, err
+	}
+
+	plan, err := warehouseMap.PlanBoxPush(boxCell, target, warehouse.PlanStrategy(planStrategy), planMaxStates)
+	slog.Info("planned box push", "strategy", plan.Strategy.String(), "states expanded", plan.StatesExpanded, "moves", len(plan.Moves), "error", err)
+	if err != nil {
+		return 0, err
+	}
+
+	planRunes := make([]rune, len(plan.Moves))
+	plannedWarehouse := warehouseMap.Clone()
+	for moveIndex, move := range plan.Moves {
+		planRunes[moveIndex] = directionRunes[move]
+		plannedWarehouse.RobotStep(move)
+	}
+	fmt.Println(string(planRunes))
+	fmt.Println(plannedWarehouse)
+	return len(plan.Moves), nil
+}
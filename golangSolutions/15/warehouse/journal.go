@@ -0,0 +1,68 @@
+package warehouse
+
+import (
+	"errors"
+	"hmcalister/AdventOfCode/gridutils"
+)
+
+var (
+	ErrorStepOutOfRange error = errors.New("step is outside of the recorded journal")
+)
+
+// The changes made by a single call to RobotStep.
+type JournalEntry struct {
+	Direction gridutils.Direction
+
+	// The robot position before and after the step, which are equal if the robot was blocked
+	RobotFrom gridutils.Coordinate
+	RobotTo   gridutils.Coordinate
+
+	// The indices (see Warehouse.Boxes) of all boxes moved one cell in Direction by the step
+	MovedBoxes []int
+}
+
+// A record of every robot step taken in a warehouse, allowing steps to be undone and redone.
+//
+// Every step is recorded, even when the robot is blocked, so step N of the journal is always move N of the input.
+// Taking a new step after undoing discards the undone steps.
+type MoveJournal struct {
+	entries    []JournalEntry
+	numApplied int
+}
+
+// Get the number of steps currently applied to the warehouse
+func (journal *MoveJournal) CurrentStep() int {
+	return journal.numApplied
+}
+
+// Get the number of steps recorded, including any that have been undone
+func (journal *MoveJournal) NumSteps() int {
+	return len(journal.entries)
+}
+
+func (journal *MoveJournal) Entries() []JournalEntry {
+	return journal.entries
+}
+
+func (journal *MoveJournal) record(entry JournalEntry) {
+	journal.entries = append(journal.entries[:journal.numApplied], entry)
+	journal.numApplied += 1
+}
+
+// Get the last applied entry, marking it as no longer applied. Returns false if there is nothing to undo.
+func (journal *MoveJournal) stepBack() (JournalEntry, bool) {
+	if journal.numApplied == 0 {
+		return JournalEntry{}, false
+	}
+	journal.numApplied -= 1
+	return journal.entries[journal.numApplied], true
+}
+
+// Get the next undone entry, marking it as applied. Returns false if there is nothing to redo.
+func (journal *MoveJournal) stepForward() (JournalEntry, bool) {
+	if journal.numApplied == len(journal.entries) {
+		return JournalEntry{}, false
+	}
+	journal.numApplied += 1
+	return journal.entries[journal.numApplied-1], true
+}
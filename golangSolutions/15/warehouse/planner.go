@@ -0,0 +1,438 @@
+package warehouse
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hmcalister/AdventOfCode/gridutils"
+	"slices"
+
+	arrayqueue "github.com/hmcalister/Go-DSA/queue/ArrayQueue"
+	hashset "github.com/hmcalister/Go-DSA/set/HashSet"
+)
+
+//go:generate stringer --type PlanStrategy
+type PlanStrategy int
+
+const (
+	// Breadth first search over warehouse states
+	PLAN_STRATEGY_BFS PlanStrategy = 0
+
+	// A* search over warehouse states, using the distance of the box from the target as the heuristic
+	PLAN_STRATEGY_ASTAR PlanStrategy = 1
+
+	// The default limit on the number of states a plan may expand
+	DEFAULT_PLAN_MAX_STATES int = 1 << 20
+)
+
+var (
+	ErrorUnknownPlanStrategy error = errors.New("unknown plan strategy")
+	ErrorNoBoxAtCell         error = errors.New("no box occupies the given cell")
+	ErrorTargetBlocked       error = errors.New("box does not fit at the target without overlapping a wall")
+	ErrorBoxDeadlocked       error = errors.New("box can never be pushed to the target")
+	ErrorNoPlanFound         error = errors.New("no sequence of moves pushes the box to the target")
+	ErrorPlanLimitExceeded   error = errors.New("plan search expanded too many states")
+
+	orthogonalDirections = []gridutils.Direction{gridutils.DIRECTION_UP, gridutils.DIRECTION_RIGHT, gridutils.DIRECTION_DOWN, gridutils.DIRECTION_LEFT}
+)
+
+// The result of planning a box push
+type Plan struct {
+	Strategy PlanStrategy
+
+	// The robot moves, in order, that push the box to the target
+	Moves []gridutils.Direction
+
+	// The number of warehouse states removed from the openset and expanded
+	StatesExpanded int
+}
+
+// Get a copy of the warehouse in its current state, with an empty journal
+func (warehouse *Warehouse) Clone() *Warehouse {
+	clone := &Warehouse{
+		// Walls never change, so can be shared
+		wallMap:       warehouse.wallMap,
+		boxes:         make([]Box, len(warehouse.boxes)),
+		boxCellMap:    make(map[gridutils.Coordinate]int, len(warehouse.boxCellMap)),
+		robotPosition: warehouse.robotPosition,
+		mapWidth:      warehouse.mapWidth,
+		mapHeight:     warehouse.mapHeight,
+	}
+	for boxIndex, box := range warehouse.boxes {
+		clone.boxes[boxIndex] = Box{slices.Clone(box.Cells)}
+	}
+	for cell, boxIndex := range warehouse.boxCellMap {
+		clone.boxCellMap[cell] = boxIndex
+	}
+	return clone
+}
+
+// --------------------------------------------------------------------------------
+// Deadlock detection
+
+// Determine if the box could ever be pushed in the given direction, assuming the boxes marked as movable can
+// get out of the way. The robot, or a box it pushes, must be able to stand behind at least one cell of the box.
+func (warehouse *Warehouse) couldEverPush(boxIndex int, direction gridutils.Direction, movable []bool) bool {
+	oppositeDirection := direction.RotateLeft().RotateLeft()
+	hasPushingCell := false
+	for _, cell := range warehouse.boxes[boxIndex].Cells {
+		frontCell := cell.Step(direction)
+		if warehouse.wallMap.Contains(frontCell) {
+			return false
+		}
+		if frontBoxIndex, ok := warehouse.boxCellMap[frontCell]; ok && frontBoxIndex != boxIndex && !movable[frontBoxIndex] {
+			return false
+		}
+
+		behindCell := cell.Step(oppositeDirection)
+		behindBoxIndex, isBoxCell := warehouse.boxCellMap[behindCell]
+		if !warehouse.wallMap.Contains(behindCell) && (!isBoxCell || behindBoxIndex != boxIndex) {
+			hasPushingCell = true
+		}
+	}
+	return hasPushingCell
+}
+
+// Find the indices of the boxes that can never move again, whatever the robot does.
+//
+// This is the freeze deadlock of Sokoban: a box is frozen if, in every direction, it is blocked by a wall,
+// by a frozen box, or has only walls behind it to push from. Boxes are marked movable until nothing changes,
+// and any box left unmarked is frozen. Frozen boxes certainly cannot move, though some boxes that are
+// not reported may also be stuck (for example, behind a box the robot cannot reach).
+func (warehouse *Warehouse) FrozenBoxes() []int {
+	movable := make([]bool, len(warehouse.boxes))
+	changed := true
+	for changed {
+		changed = false
+		for boxIndex := range warehouse.boxes {
+			if movable[boxIndex] {
+				continue
+			}
+			for _, direction := range orthogonalDirections {
+				if warehouse.couldEverPush(boxIndex, direction, movable) {
+					movable[boxIndex] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	frozenBoxes := make([]int, 0)
+	for boxIndex, isMovable := range movable {
+		if !isMovable {
+			frozenBoxes = append(frozenBoxes, boxIndex)
+		}
+	}
+	return frozenBoxes
+}
+
+// Get the cells of the box if it were moved so its top left corner is at the given position
+func translateBoxCells(box Box, topLeft gridutils.Coordinate) []gridutils.Coordinate {
+	boxTopLeft := box.TopLeft()
+	cells := make([]gridutils.Coordinate, len(box.Cells))
+	for cellIndex, cell := range box.Cells {
+		cells[cellIndex] = gridutils.Coordinate{X: cell.X - boxTopLeft.X + topLeft.X, Y: cell.Y - boxTopLeft.Y + topLeft.Y}
+	}
+	return cells
+}
+
+// Determine if the box fits with its top left corner at the given position, ignoring other boxes
+func (warehouse *Warehouse) boxFits(box Box, topLeft gridutils.Coordinate) bool {
+	for _, cell := range translateBoxCells(box, topLeft) {
+		if cell.X < 0 || cell.X >= warehouse.mapWidth || cell.Y < 0 || cell.Y >= warehouse.mapHeight || warehouse.wallMap.Contains(cell) {
+			return false
+		}
+	}
+	return true
+}
+
+// Find every top left position from which the box could be pushed to the target, ignoring other boxes.
+//
+// This generalizes the dead squares of Sokoban to boxes of any shape: the search pulls the box backwards from
+// the target, which is possible whenever the box fits at the previous position and the robot could stand behind it.
+// A box anywhere else can never reach the target.
+func (warehouse *Warehouse) LiveBoxPositions(boxIndex int, target gridutils.Coordinate) (*hashset.HashSet[gridutils.Coordinate], error) {
+	box := warehouse.boxes[boxIndex]
+	if !warehouse.boxFits(box, target) {
+		return nil, ErrorTargetBlocked
+	}
+
+	livePositions := hashset.New[gridutils.Coordinate]()
+	livePositions.Add(target)
+	positionQueue := arrayqueue.New[gridutils.Coordinate]()
+	positionQueue.Add(target)
+	for positionQueue.Size() > 0 {
+		position, _ := positionQueue.Remove()
+		for _, direction := range orthogonalDirections {
+			// The box reaches this position by a push in the given direction from the previous position
+			oppositeDirection := direction.RotateLeft().RotateLeft()
+			previousPosition := position.Step(oppositeDirection)
+			if livePositions.Contains(previousPosition) || !warehouse.boxFits(box, previousPosition) {
+				continue
+			}
+			previousCells := translateBoxCells(box, previousPosition)
+			for _, cell := range previousCells {
+				robotCell := cell.Step(oppositeDirection)
+				if !slices.Contains(previousCells, robotCell) && !warehouse.wallMap.Contains(robotCell) {
+					livePositions.Add(previousPosition)
+					positionQueue.Add(previousPosition)
+					break
+				}
+			}
+		}
+	}
+	return livePositions, nil
+}
+
+// --------------------------------------------------------------------------------
+// Planning
+
+// A box moved from its position when planning started
+type boxOffset struct {
+	boxIndex int
+	offset   gridutils.Coordinate
+}
+
+// A warehouse state during planning. Only the boxes that have moved are stored, sorted by index,
+// as most states differ from the start by only a few boxes.
+type plannerState struct {
+	robotPosition gridutils.Coordinate
+	boxOffsets    []boxOffset
+}
+
+func (state plannerState) key() string {
+	key := binary.AppendVarint(nil, int64(state.robotPosition.X))
+	key = binary.AppendVarint(key, int64(state.robotPosition.Y))
+	for _, moved := range state.boxOffsets {
+		key = binary.AppendVarint(key, int64(moved.boxIndex))
+		key = binary.AppendVarint(key, int64(moved.offset.X))
+		key = binary.AppendVarint(key, int64(moved.offset.Y))
+	}
+	return string(key)
+}
+
+func (state plannerState) offsetOf(boxIndex int) gridutils.Coordinate {
+	index, found := slices.BinarySearchFunc(state.boxOffsets, boxIndex, func(moved boxOffset, boxIndex int) int {
+		return moved.boxIndex - boxIndex
+	})
+	if !found {
+		return gridutils.Coordinate{}
+	}
+	return state.boxOffsets[index].offset
+}
+
+// Get the state reached by the robot stepping in the given direction and pushing the given boxes
+func (state plannerState) step(direction gridutils.Direction, robotPosition gridutils.Coordinate, pushedBoxes []int) plannerState {
+	offsets := make(map[int]gridutils.Coordinate, len(state.boxOffsets)+len(pushedBoxes))
+	for _, moved := range state.boxOffsets {
+		offsets[moved.boxIndex] = moved.offset
+	}
+	for _, boxIndex := range pushedBoxes {
+		offsets[boxIndex] = offsets[boxIndex].Step(direction)
+	}
+
+	nextState := plannerState{robotPosition: robotPosition, boxOffsets: make([]boxOffset, 0, len(offsets))}
+	for boxIndex, offset := range offsets {
+		if offset != (gridutils.Coordinate{}) {
+			nextState.boxOffsets = append(nextState.boxOffsets, boxOffset{boxIndex, offset})
+		}
+	}
+	slices.SortFunc(nextState.boxOffsets, func(a, b boxOffset) int {
+		return a.boxIndex - b.boxIndex
+	})
+	return nextState
+}
+
+// Searches over states of a private copy of the warehouse, stepping the robot with RobotStep
+type boxPlanner struct {
+	warehouse    *Warehouse
+	initialCells [][]gridutils.Coordinate
+	loadedState  plannerState
+
+	boxIndex       int
+	initialTopLeft gridutils.Coordinate
+	target         gridutils.Coordinate
+}
+
+// Move the robot and boxes of the working warehouse to match the state.
+// Every box that moves is removed before any are placed, as a box may move into the previous position of another.
+func (planner *boxPlanner) load(state plannerState) {
+	changedBoxes := make([]int, 0, len(planner.loadedState.boxOffsets)+len(state.boxOffsets))
+	for _, moved := range planner.loadedState.boxOffsets {
+		changedBoxes = append(changedBoxes, moved.boxIndex)
+	}
+	for _, moved := range state.boxOffsets {
+		changedBoxes = append(changedBoxes, moved.boxIndex)
+	}
+
+	for _, boxIndex := range changedBoxes {
+		for _, cell := range planner.warehouse.boxes[boxIndex].Cells {
+			delete(planner.warehouse.boxCellMap, cell)
+		}
+	}
+	for _, boxIndex := range changedBoxes {
+		offset := state.offsetOf(boxIndex)
+		cells := make([]gridutils.Coordinate, len(planner.initialCells[boxIndex]))
+		for cellIndex, cell := range planner.initialCells[boxIndex] {
+			cells[cellIndex] = gridutils.Coordinate{X: cell.X + offset.X, Y: cell.Y + offset.Y}
+			planner.warehouse.boxCellMap[cells[cellIndex]] = boxIndex
+		}
+		planner.warehouse.boxes[boxIndex].Cells = cells
+	}
+	planner.warehouse.robotPosition = state.robotPosition
+	planner.loadedState = state
+}
+
+func (planner *boxPlanner) boxTopLeft(state plannerState) gridutils.Coordinate {
+	offset := state.offsetOf(planner.boxIndex)
+	return gridutils.Coordinate{X: planner.initialTopLeft.X + offset.X, Y: planner.initialTopLeft.Y + offset.Y}
+}
+
+// A lower bound on the moves left: every move shifts the box by at most one cell, so the box must be pushed at
+// least its distance from the target. The robot's distance from the box is not counted, as the robot may push
+// the box through a chain of other boxes without ever standing next to it.
+func (planner *boxPlanner) heuristic(state plannerState) int {
+	return manhattanDistance(planner.boxTopLeft(state), planner.target)
+}
+
+func manhattanDistance(a, b gridutils.Coordinate) int {
+	return max(a.X-b.X, b.X-a.X) + max(a.Y-b.Y, b.Y-a.Y)
+}
+
+// An item in the plan openset. Items are never updated in place; stale items are skipped when removed.
+type planItem struct {
+	state  plannerState
+	gScore int
+	fScore int
+}
+
+// An openset holding a queue of items for each f score. Every move costs one and the heuristic is consistent
+// (it changes by at most one per move), so f scores never decrease during the search, and items can be removed from the lowest non-empty queue
+// in constant time (the heap backed priority queue slows down badly with the number of states a plan explores).
+type planOpenset struct {
+	buckets       []*arrayqueue.ArrayQueue[planItem]
+	currentBucket int
+	size          int
+}
+
+func (openset *planOpenset) add(item planItem) {
+	for len(openset.buckets) <= item.fScore {
+		openset.buckets = append(openset.buckets, arrayqueue.New[planItem]())
+	}
+	openset.buckets[item.fScore].Add(item)
+	openset.currentBucket = min(openset.currentBucket, item.fScore)
+	openset.size += 1
+}
+
+// Remove an item with the lowest f score. The openset must not be empty.
+func (openset *planOpenset) remove() planItem {
+	for openset.buckets[openset.currentBucket].Size() == 0 {
+		openset.currentBucket += 1
+	}
+	item, _ := openset.buckets[openset.currentBucket].Remove()
+	openset.size -= 1
+	return item
+}
+
+// A step taken to reach a state, for reconstructing the plan
+type planStep struct {
+	previousKey string
+	direction   gridutils.Direction
+}
+
+// Find a shortest sequence of robot moves that pushes the box occupying the given cell so that its top left corner
+// is at the target. The warehouse itself is not changed.
+//
+// Any box may be pushed along the way. States where the box can no longer reach the target (see LiveBoxPositions)
+// are pruned, and the search gives up after expanding maxStates states.
+func (warehouse *Warehouse) PlanBoxPush(boxCell gridutils.Coordinate, target gridutils.Coordinate, strategy PlanStrategy, maxStates int) (Plan, error) {
+	plan := Plan{Strategy: strategy}
+	if strategy != PLAN_STRATEGY_BFS && strategy != PLAN_STRATEGY_ASTAR {
+		return plan, ErrorUnknownPlanStrategy
+	}
+	boxIndex, ok := warehouse.boxCellMap[boxCell]
+	if !ok {
+		return plan, fmt.Errorf("%w: %v", ErrorNoBoxAtCell, boxCell)
+	}
+	livePositions, err := warehouse.LiveBoxPositions(boxIndex, target)
+	if err != nil {
+		return plan, err
+	}
+	if !livePositions.Contains(warehouse.boxes[boxIndex].TopLeft()) {
+		return plan, ErrorBoxDeadlocked
+	}
+
+	planner := &boxPlanner{
+		warehouse:      warehouse.Clone(),
+		initialCells:   make([][]gridutils.Coordinate, len(warehouse.boxes)),
+		boxIndex:       boxIndex,
+		initialTopLeft: warehouse.boxes[boxIndex].TopLeft(),
+		target:         target,
+	}
+	for index, box := range warehouse.boxes {
+		planner.initialCells[index] = box.Cells
+	}
+
+	heuristic := func(state plannerState) int {
+		if strategy == PLAN_STRATEGY_BFS {
+			return 0
+		}
+		return planner.heuristic(state)
+	}
+	startState := plannerState{robotPosition: warehouse.robotPosition}
+	startKey := startState.key()
+	gScores := map[string]int{startKey: 0}
+	cameFrom := make(map[string]planStep)
+	openset := &planOpenset{}
+	openset.add(planItem{startState, 0, heuristic(startState)})
+
+	for openset.size > 0 {
+		item := openset.remove()
+		itemKey := item.state.key()
+		if item.gScore > gScores[itemKey] {
+			continue
+		}
+		plan.StatesExpanded += 1
+		if plan.StatesExpanded > maxStates {
+			return plan, ErrorPlanLimitExceeded
+		}
+		if planner.boxTopLeft(item.state) == target {
+			plan.Moves = reconstructPlan(cameFrom, itemKey, startKey)
+			return plan, nil
+		}
+
+		planner.load(item.state)
+		for _, direction := range orthogonalDirections {
+			planner.warehouse.RobotStep(direction)
+			entry := planner.warehouse.journal.Entries()[planner.warehouse.journal.CurrentStep()-1]
+			planner.warehouse.Undo()
+			if entry.RobotFrom == entry.RobotTo {
+				continue
+			}
+
+			nextState := item.state.step(direction, entry.RobotTo, entry.MovedBoxes)
+			if slices.Contains(entry.MovedBoxes, boxIndex) && !livePositions.Contains(planner.boxTopLeft(nextState)) {
+				continue
+			}
+			nextKey := nextState.key()
+			nextGScore := item.gScore + 1
+			if previousGScore, ok := gScores[nextKey]; ok && previousGScore <= nextGScore {
+				continue
+			}
+			gScores[nextKey] = nextGScore
+			cameFrom[nextKey] = planStep{itemKey, direction}
+			openset.add(planItem{nextState, nextGScore, nextGScore + heuristic(nextState)})
+		}
+	}
+	return plan, ErrorNoPlanFound
+}
+
+func reconstructPlan(cameFrom map[string]planStep, finalKey string, startKey string) []gridutils.Direction {
+	moves := make([]gridutils.Direction, 0)
+	for key := finalKey; key != startKey; key = cameFrom[key].previousKey {
+		moves = append(moves, cameFrom[key].direction)
+	}
+	slices.Reverse(moves)
+	return moves
+}
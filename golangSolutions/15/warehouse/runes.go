@@ -5,4 +5,9 @@ const (
 	BOX_RUNE   rune = 'O'
 	ROBOT_RUNE rune = '@'
 	EMPTY_RUNE rune = '.'
+
+	// Boxes that are a horizontal bar of more than one cell are drawn as [, followed by = for each middle cell, then ]
+	BOX_LEFT_RUNE   rune = '['
+	BOX_MIDDLE_RUNE rune = '='
+	BOX_RIGHT_RUNE  rune = ']'
 )
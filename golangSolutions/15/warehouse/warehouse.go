@@ -0,0 +1,471 @@
+package warehouse
+
+import (
+	"errors"
+	"fmt"
+	"hmcalister/AdventOfCode/gridutils"
+	"log/slog"
+	"slices"
+
+	hashset "github.com/hmcalister/Go-DSA/set/HashSet"
+	arraystack "github.com/hmcalister/Go-DSA/stack/ArrayStack"
+)
+
+var (
+	ErrorInvalidScale     error = errors.New("warehouse scale must be at least 1")
+	ErrorUnexpectedRune   error = errors.New("unexpected rune in warehouse layout")
+	ErrorUnterminatedBox  error = errors.New("box opened with '[' is not closed with ']' on the same row")
+	ErrorCellNotEmpty     error = errors.New("box cell is already occupied by a wall, box, or the robot")
+	ErrorBoxNotConnected  error = errors.New("box cells must be orthogonally connected")
+	ErrorNoBoxCells       error = errors.New("box must have at least one cell")
+	ErrorBoxCellsRepeated error = errors.New("box cells must be distinct")
+)
+
+// A box occupying any (orthogonally connected) set of cells, i.e. a polyomino.
+//
+// The cells of a box must not be modified, as they are shared with the warehouse.
+type Box struct {
+	Cells []gridutils.Coordinate
+}
+
+// The top left corner of the bounding box of the box, which is what the GPS coordinate of the box measures.
+func (box Box) TopLeft() gridutils.Coordinate {
+	topLeft := box.Cells[0]
+	for _, cell := range box.Cells {
+		topLeft.X = min(topLeft.X, cell.X)
+		topLeft.Y = min(topLeft.Y, cell.Y)
+	}
+	return topLeft
+}
+
+func (box Box) GPS() int {
+	topLeft := box.TopLeft()
+	return 100*topLeft.Y + topLeft.X
+}
+
+// Determine if the box is a single row of cells, which are drawn as O (for a single cell) or [=...=]
+func (box Box) isHorizontalBar() bool {
+	topLeft := box.TopLeft()
+	for _, cell := range box.Cells {
+		if cell.Y != topLeft.Y || cell.X >= topLeft.X+len(box.Cells) {
+			return false
+		}
+	}
+	return true
+}
+
+// A warehouse of walls and boxes, with a robot pushing the boxes around.
+//
+// Boxes may be any shape. Pushing a box pushes every box any of its cells would move into, and so on,
+// and the whole group only moves if none of them would move into a wall.
+type Warehouse struct {
+	wallMap       *hashset.HashSet[gridutils.Coordinate]
+	boxes         []Box
+	boxCellMap    map[gridutils.Coordinate]int
+	robotPosition gridutils.Coordinate
+	mapWidth      int
+	mapHeight     int
+	journal       MoveJournal
+}
+
+func newEmptyWarehouse(mapWidth, mapHeight int) *Warehouse {
+	return &Warehouse{
+		wallMap:    hashset.New[gridutils.Coordinate](),
+		boxes:      make([]Box, 0),
+		boxCellMap: make(map[gridutils.Coordinate]int),
+		mapWidth:   mapWidth,
+		mapHeight:  mapHeight,
+	}
+}
+
+// Create a warehouse from the puzzle map, where every cell is stretched horizontally by the scale factor.
+//
+// Walls fill all of their stretched cells, boxes become a single box as wide as the scale, and the robot
+// is placed in the leftmost stretched cell. Scale 1 is the warehouse of part 1, and scale 2 the warehouse of part 2.
+func NewWarehouse(warehouseMapStrs []string, scale int) (*Warehouse, error) {
+	if scale < 1 {
+		return nil, ErrorInvalidScale
+	}
+	warehouse := newEmptyWarehouse(scale*len(warehouseMapStrs[0]), len(warehouseMapStrs))
+
+	for y, row := range warehouseMapStrs {
+		slog.Debug("parsing row", "row", row)
+		for x, cell := range row {
+			stretchedCells := make([]gridutils.Coordinate, scale)
+			for offset := range scale {
+				stretchedCells[offset] = gridutils.Coordinate{X: scale*x + offset, Y: y}
+			}
+			switch cell {
+			case WALL_RUNE:
+				slog.Debug("found wall", "coordinates", stretchedCells)
+				for _, wallCoordinate := range stretchedCells {
+					warehouse.wallMap.Add(wallCoordinate)
+				}
+			case BOX_RUNE:
+				slog.Debug("found box", "coordinates", stretchedCells)
+				if err := warehouse.addBox(stretchedCells); err != nil {
+					return nil, err
+				}
+			case ROBOT_RUNE:
+				slog.Debug("found robot", "coordinate", stretchedCells[0])
+				warehouse.robotPosition = stretchedCells[0]
+			case EMPTY_RUNE:
+			default:
+				return nil, fmt.Errorf("%w: %q at row %d column %d", ErrorUnexpectedRune, cell, y, x)
+			}
+		}
+	}
+
+	return warehouse, nil
+}
+
+// Create a warehouse from a layout drawn cell by cell, as produced by Warehouse.String.
+//
+// Walls, the robot, and empty cells are drawn as in the puzzle map. A box may be drawn as O (a single cell),
+// as [ followed by any number of = then ] (a horizontal bar, like the boxes of part 2), or as any other letter,
+// in which case each orthogonally connected group of cells with the same letter is a single box.
+func ParseWarehouseLayout(layout []string) (*Warehouse, error) {
+	warehouse := newEmptyWarehouse(len(layout[0]), len(layout))
+	layoutRunes := make([][]rune, len(layout))
+	for y, row := range layout {
+		layoutRunes[y] = []rune(row)
+	}
+	runeAt := func(coordinate gridutils.Coordinate) rune {
+		if coordinate.Y < 0 || coordinate.Y >= len(layoutRunes) || coordinate.X < 0 || coordinate.X >= len(layoutRunes[coordinate.Y]) {
+			return WALL_RUNE
+		}
+		return layoutRunes[coordinate.Y][coordinate.X]
+	}
+
+	visitedBoxCells := hashset.New[gridutils.Coordinate]()
+	for y, row := range layoutRunes {
+		for x := 0; x < len(row); x += 1 {
+			currentCoordinate := gridutils.Coordinate{X: x, Y: y}
+			cell := row[x]
+			switch {
+			case cell == WALL_RUNE:
+				warehouse.wallMap.Add(currentCoordinate)
+			case cell == ROBOT_RUNE:
+				warehouse.robotPosition = currentCoordinate
+			case cell == EMPTY_RUNE:
+			case cell == BOX_RUNE:
+				if err := warehouse.addBox([]gridutils.Coordinate{currentCoordinate}); err != nil {
+					return nil, err
+				}
+			case cell == BOX_LEFT_RUNE:
+				boxCells := []gridutils.Coordinate{currentCoordinate}
+				for x += 1; x < len(row) && row[x] == BOX_MIDDLE_RUNE; x += 1 {
+					boxCells = append(boxCells, gridutils.Coordinate{X: x, Y: y})
+				}
+				if x == len(row) || row[x] != BOX_RIGHT_RUNE {
+					return nil, fmt.Errorf("%w: box at row %d column %d", ErrorUnterminatedBox, y, currentCoordinate.X)
+				}
+				boxCells = append(boxCells, gridutils.Coordinate{X: x, Y: y})
+				if err := warehouse.addBox(boxCells); err != nil {
+					return nil, err
+				}
+			case ('a' <= cell && cell <= 'z') || ('A' <= cell && cell <= 'Z'):
+				if visitedBoxCells.Contains(currentCoordinate) {
+					continue
+				}
+				// Flood fill the connected cells with the same letter
+				boxCells := make([]gridutils.Coordinate, 0)
+				boxCellStack := arraystack.New[gridutils.Coordinate]()
+				boxCellStack.Add(currentCoordinate)
+				visitedBoxCells.Add(currentCoordinate)
+				for boxCellStack.Size() > 0 {
+					boxCell, _ := boxCellStack.Remove()
+					boxCells = append(boxCells, boxCell)
+					for _, neighbor := range boxCell.GetOrthogonalNeighbors() {
+						if runeAt(neighbor) == cell && !visitedBoxCells.Contains(neighbor) {
+							visitedBoxCells.Add(neighbor)
+							boxCellStack.Add(neighbor)
+						}
+					}
+				}
+				if err := warehouse.addBox(boxCells); err != nil {
+					return nil, err
+				}
+			default:
+				return nil, fmt.Errorf("%w: %q at row %d column %d", ErrorUnexpectedRune, cell, y, x)
+			}
+		}
+	}
+
+	return warehouse, nil
+}
+
+// Add a box occupying the given cells, which must be connected and not contain a wall, another box, or the robot.
+func (warehouse *Warehouse) AddBox(cells []gridutils.Coordinate) error {
+	if slices.Contains(cells, warehouse.robotPosition) {
+		return fmt.Errorf("%w: %v", ErrorCellNotEmpty, warehouse.robotPosition)
+	}
+	return warehouse.addBox(cells)
+}
+
+// Add a box without checking the robot position, which is not known until parsing is complete
+func (warehouse *Warehouse) addBox(cells []gridutils.Coordinate) error {
+	if len(cells) == 0 {
+		return ErrorNoBoxCells
+	}
+	for index, cell := range cells {
+		if slices.Contains(cells[:index], cell) {
+			return ErrorBoxCellsRepeated
+		}
+		_, isBoxCell := warehouse.boxCellMap[cell]
+		if isBoxCell || warehouse.wallMap.Contains(cell) {
+			return fmt.Errorf("%w: %v", ErrorCellNotEmpty, cell)
+		}
+	}
+
+	// Walk the cells from the first to check they are connected
+	connectedCells := hashset.New[gridutils.Coordinate]()
+	connectedCells.Add(cells[0])
+	cellStack := arraystack.New[gridutils.Coordinate]()
+	cellStack.Add(cells[0])
+	for cellStack.Size() > 0 {
+		cell, _ := cellStack.Remove()
+		for _, neighbor := range cell.GetOrthogonalNeighbors() {
+			if slices.Contains(cells, neighbor) && !connectedCells.Contains(neighbor) {
+				connectedCells.Add(neighbor)
+				cellStack.Add(neighbor)
+			}
+		}
+	}
+	if connectedCells.Size() != len(cells) {
+		return ErrorBoxNotConnected
+	}
+
+	boxIndex := len(warehouse.boxes)
+	warehouse.boxes = append(warehouse.boxes, Box{slices.Clone(cells)})
+	for _, cell := range cells {
+		warehouse.boxCellMap[cell] = boxIndex
+	}
+	return nil
+}
+
+func (warehouse *Warehouse) Width() int {
+	return warehouse.mapWidth
+}
+
+func (warehouse *Warehouse) Height() int {
+	return warehouse.mapHeight
+}
+
+func (warehouse *Warehouse) RobotPosition() gridutils.Coordinate {
+	return warehouse.robotPosition
+}
+
+// Get every box in the warehouse. The index of each box is fixed for the lifetime of the warehouse.
+func (warehouse *Warehouse) Boxes() []Box {
+	return warehouse.boxes
+}
+
+func (warehouse *Warehouse) IsWall(coordinate gridutils.Coordinate) bool {
+	return warehouse.wallMap.Contains(coordinate)
+}
+
+// Get the index of the box occupying the given cell, if any
+func (warehouse *Warehouse) BoxAt(coordinate gridutils.Coordinate) (int, bool) {
+	boxIndex, ok := warehouse.boxCellMap[coordinate]
+	return boxIndex, ok
+}
+
+// Choose a letter for each box that is not a horizontal bar, such that no two touching boxes share a letter
+// (so the layout parses back into the same boxes).
+func (warehouse *Warehouse) polyominoLetters() map[int]rune {
+	letters := make(map[int]rune)
+	for boxIndex, box := range warehouse.boxes {
+		if box.isHorizontalBar() {
+			continue
+		}
+		usedLetters := make(map[rune]bool)
+		for _, cell := range box.Cells {
+			for _, neighbor := range cell.GetOrthogonalNeighbors() {
+				if neighborBoxIndex, ok := warehouse.boxCellMap[neighbor]; ok {
+					usedLetters[letters[neighborBoxIndex]] = true
+				}
+			}
+		}
+		for letter := 'a'; letter <= 'z'; letter += 1 {
+			if !usedLetters[letter] {
+				letters[boxIndex] = letter
+				break
+			}
+		}
+	}
+	return letters
+}
+
+func (warehouse *Warehouse) String() string {
+	s := make([]rune, warehouse.mapHeight*(warehouse.mapWidth+1))
+	polyominoLetters := warehouse.polyominoLetters()
+
+	currentStrIndex := 0
+	for y := 0; y < warehouse.mapHeight; y += 1 {
+		for x := 0; x < warehouse.mapWidth; x += 1 {
+			coord := gridutils.Coordinate{X: x, Y: y}
+			boxIndex, isBoxCell := warehouse.boxCellMap[coord]
+
+			if warehouse.robotPosition.Equal(coord) {
+				s[currentStrIndex] = ROBOT_RUNE
+			} else if warehouse.wallMap.Contains(coord) {
+				s[currentStrIndex] = WALL_RUNE
+			} else if isBoxCell {
+				box := warehouse.boxes[boxIndex]
+				switch {
+				case !box.isHorizontalBar():
+					s[currentStrIndex] = polyominoLetters[boxIndex]
+				case len(box.Cells) == 1:
+					s[currentStrIndex] = BOX_RUNE
+				case box.TopLeft().Equal(coord):
+					s[currentStrIndex] = BOX_LEFT_RUNE
+				case box.TopLeft().X+len(box.Cells)-1 == x:
+					s[currentStrIndex] = BOX_RIGHT_RUNE
+				default:
+					s[currentStrIndex] = BOX_MIDDLE_RUNE
+				}
+			} else {
+				s[currentStrIndex] = EMPTY_RUNE
+			}
+			currentStrIndex += 1
+		}
+		s[currentStrIndex] = '\n'
+		currentStrIndex += 1
+	}
+
+	return string(s)
+}
+
+// --------------------------------------------------------------------------------
+// Robot movement
+
+// Find every box that would be pushed by moving into the given cell in the given direction.
+//
+// Pushing a box pushes every other box that any of its cells would move into, so the pushed boxes are found
+// by a search from the box in the given cell. Returns false if the push is blocked by a wall.
+func (warehouse *Warehouse) findPushedBoxes(pushedCell gridutils.Coordinate, pushDirection gridutils.Direction) ([]int, bool) {
+	if warehouse.wallMap.Contains(pushedCell) {
+		return nil, false
+	}
+	firstBoxIndex, ok := warehouse.boxCellMap[pushedCell]
+	if !ok {
+		return nil, true
+	}
+
+	// e.g. this robot moving up pushes all four boxes, which are blocked by the wall above the upper right box
+	// ##############
+	// ##......##..##
+	// ##...[][]...##
+	// ##....[]....##
+	// ##.....@....##
+	// ##############
+	pushedBoxes := []int{firstBoxIndex}
+	pushedBoxSet := hashset.New[int]()
+	pushedBoxSet.Add(firstBoxIndex)
+	pushedBoxStack := arraystack.New[int]()
+	pushedBoxStack.Add(firstBoxIndex)
+	for pushedBoxStack.Size() > 0 {
+		boxIndex, _ := pushedBoxStack.Remove()
+		for _, cell := range warehouse.boxes[boxIndex].Cells {
+			nextCell := cell.Step(pushDirection)
+			if warehouse.wallMap.Contains(nextCell) {
+				return nil, false
+			}
+			nextBoxIndex, ok := warehouse.boxCellMap[nextCell]
+			if ok && !pushedBoxSet.Contains(nextBoxIndex) {
+				pushedBoxes = append(pushedBoxes, nextBoxIndex)
+				pushedBoxSet.Add(nextBoxIndex)
+				pushedBoxStack.Add(nextBoxIndex)
+			}
+		}
+	}
+	return pushedBoxes, true
+}
+
+// Move every given box one cell in the given direction. All boxes are removed before any are added,
+// as a box may move into the previous position of another.
+func (warehouse *Warehouse) moveBoxes(boxIndices []int, direction gridutils.Direction) {
+	for _, boxIndex := range boxIndices {
+		for _, cell := range warehouse.boxes[boxIndex].Cells {
+			delete(warehouse.boxCellMap, cell)
+		}
+	}
+	for _, boxIndex := range boxIndices {
+		movedCells := make([]gridutils.Coordinate, len(warehouse.boxes[boxIndex].Cells))
+		for cellIndex, cell := range warehouse.boxes[boxIndex].Cells {
+			movedCells[cellIndex] = cell.Step(direction)
+			warehouse.boxCellMap[movedCells[cellIndex]] = boxIndex
+		}
+		warehouse.boxes[boxIndex].Cells = movedCells
+	}
+}
+
+// Attempt to move the robot one cell in the given direction, pushing any boxes in the way.
+// If the robot or any pushed box would move into a wall, nothing moves.
+func (warehouse *Warehouse) RobotStep(stepDirection gridutils.Direction) {
+	proposedRobotPosition := warehouse.robotPosition.Step(stepDirection)
+	entry := JournalEntry{stepDirection, warehouse.robotPosition, warehouse.robotPosition, nil}
+
+	pushedBoxes, ok := warehouse.findPushedBoxes(proposedRobotPosition, stepDirection)
+	if ok {
+		warehouse.moveBoxes(pushedBoxes, stepDirection)
+		warehouse.robotPosition = proposedRobotPosition
+		entry.RobotTo = proposedRobotPosition
+		entry.MovedBoxes = pushedBoxes
+	}
+	warehouse.journal.record(entry)
+}
+
+func (warehouse *Warehouse) ComputeGPS() int {
+	totalGps := 0
+	for _, box := range warehouse.boxes {
+		currentGps := box.GPS()
+		totalGps += currentGps
+		slog.Debug("computing box gps", "box position", box.TopLeft(), "gps", currentGps, "updated total gps", totalGps)
+	}
+	return totalGps
+}
+
+// --------------------------------------------------------------------------------
+// Journal
+
+func (warehouse *Warehouse) Journal() *MoveJournal {
+	return &warehouse.journal
+}
+
+// Revert the last robot step, returning false if no steps have been taken
+func (warehouse *Warehouse) Undo() bool {
+	entry, ok := warehouse.journal.stepBack()
+	if !ok {
+		return false
+	}
+	warehouse.moveBoxes(entry.MovedBoxes, entry.Direction.RotateLeft().RotateLeft())
+	warehouse.robotPosition = entry.RobotFrom
+	return true
+}
+
+// Reapply the last undone robot step, returning false if there is nothing to redo
+func (warehouse *Warehouse) Redo() bool {
+	entry, ok := warehouse.journal.stepForward()
+	if !ok {
+		return false
+	}
+	warehouse.moveBoxes(entry.MovedBoxes, entry.Direction)
+	warehouse.robotPosition = entry.RobotTo
+	return true
+}
+
+// Undo or redo robot steps until exactly the given number of steps of the journal are applied
+func (warehouse *Warehouse) SeekToStep(step int) error {
+	if step < 0 || step > warehouse.journal.NumSteps() {
+		return ErrorStepOutOfRange
+	}
+	for warehouse.journal.CurrentStep() > step {
+		warehouse.Undo()
+	}
+	for warehouse.journal.CurrentStep() < step {
+		warehouse.Redo()
+	}
+	return nil
+}
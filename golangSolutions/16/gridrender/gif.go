@@ -0,0 +1,178 @@
+package gridrender
+
+import (
+	"bufio"
+	"compress/lzw"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+)
+
+const (
+	// GIF frame delays are measured in hundredths of a second
+	GIF_DELAY_UNITS_PER_SECOND int = 100
+
+	// The largest palette a GIF can hold
+	GIF_MAX_PALETTE_SIZE int = 256
+)
+
+var (
+	ErrorPaletteTooLarge error = errors.New("palette has too many colors")
+	ErrorFrameSize       error = errors.New("frame size does not match the animation size")
+	ErrorWriterClosed    error = errors.New("animation writer has already been closed")
+)
+
+// Writes an animated GIF one frame at a time, rather than holding every frame in memory as gif.EncodeAll requires.
+//
+// Every frame shares a single global palette, and loops forever.
+type GIFWriter struct {
+	writer  *bufio.Writer
+	width   int
+	height  int
+	palette color.Palette
+
+	// The number of bits needed to index the palette, as stored in the GIF (at least 2)
+	paletteBits int
+	closed      bool
+}
+
+// Start a GIF of the given size and palette, writing the header to the writer immediately.
+func NewGIFWriter(writer io.Writer, width, height int, palette color.Palette) (*GIFWriter, error) {
+	if len(palette) > GIF_MAX_PALETTE_SIZE {
+		return nil, ErrorPaletteTooLarge
+	}
+	paletteBits := 2
+	for 1<<paletteBits < len(palette) {
+		paletteBits += 1
+	}
+
+	gifWriter := &GIFWriter{
+		writer:      bufio.NewWriter(writer),
+		width:       width,
+		height:      height,
+		palette:     palette,
+		paletteBits: paletteBits,
+	}
+	if err := gifWriter.writeHeader(); err != nil {
+		return nil, err
+	}
+	return gifWriter, nil
+}
+
+func (gifWriter *GIFWriter) writeUint16(value int) {
+	binary.Write(gifWriter.writer, binary.LittleEndian, uint16(value))
+}
+
+func (gifWriter *GIFWriter) writeHeader() error {
+	gifWriter.writer.WriteString("GIF89a")
+
+	// Logical screen descriptor, with a global color table
+	gifWriter.writeUint16(gifWriter.width)
+	gifWriter.writeUint16(gifWriter.height)
+	gifWriter.writer.WriteByte(0x80 | 0x70 | byte(gifWriter.paletteBits-1))
+	gifWriter.writer.WriteByte(0) // background color index
+	gifWriter.writer.WriteByte(0) // pixel aspect ratio
+
+	// Global color table, padded to a power of two
+	for index := range 1 << gifWriter.paletteBits {
+		if index < len(gifWriter.palette) {
+			r, g, b, _ := gifWriter.palette[index].RGBA()
+			gifWriter.writer.Write([]byte{byte(r >> 8), byte(g >> 8), byte(b >> 8)})
+		} else {
+			gifWriter.writer.Write([]byte{0, 0, 0})
+		}
+	}
+
+	// Application extension to loop forever
+	gifWriter.writer.Write([]byte{0x21, 0xFF, 0x0B})
+	gifWriter.writer.WriteString("NETSCAPE2.0")
+	gifWriter.writer.Write([]byte{0x03, 0x01})
+	gifWriter.writeUint16(0)
+	_, err := gifWriter.writer.Write([]byte{0x00})
+	return err
+}
+
+// Splits image data into the sub-blocks of at most 255 bytes the GIF format requires
+type gifBlockWriter struct {
+	writer *bufio.Writer
+	block  [255]byte
+	length int
+}
+
+func (blockWriter *gifBlockWriter) Write(data []byte) (int, error) {
+	for _, dataByte := range data {
+		blockWriter.block[blockWriter.length] = dataByte
+		blockWriter.length += 1
+		if blockWriter.length == len(blockWriter.block) {
+			if err := blockWriter.flush(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return len(data), nil
+}
+
+func (blockWriter *gifBlockWriter) flush() error {
+	if blockWriter.length == 0 {
+		return nil
+	}
+	blockWriter.writer.WriteByte(byte(blockWriter.length))
+	_, err := blockWriter.writer.Write(blockWriter.block[:blockWriter.length])
+	blockWriter.length = 0
+	return err
+}
+
+// Write a frame, displayed for the given delay in hundredths of a second.
+// The frame must be the size of the animation and use the animation palette.
+func (gifWriter *GIFWriter) WriteFrame(frame *image.Paletted, delay int) error {
+	if gifWriter.closed {
+		return ErrorWriterClosed
+	}
+	bounds := frame.Bounds()
+	if bounds.Dx() != gifWriter.width || bounds.Dy() != gifWriter.height {
+		return ErrorFrameSize
+	}
+
+	// Graphic control extension, holding the frame delay
+	gifWriter.writer.Write([]byte{0x21, 0xF9, 0x04, 0x00})
+	gifWriter.writeUint16(delay)
+	gifWriter.writer.Write([]byte{0x00, 0x00})
+
+	// Image descriptor, covering the whole animation and using the global color table
+	gifWriter.writer.WriteByte(0x2C)
+	gifWriter.writeUint16(0)
+	gifWriter.writeUint16(0)
+	gifWriter.writeUint16(gifWriter.width)
+	gifWriter.writeUint16(gifWriter.height)
+	gifWriter.writer.WriteByte(0x00)
+
+	// LZW compressed pixel indices, in sub-blocks
+	gifWriter.writer.WriteByte(byte(gifWriter.paletteBits))
+	blockWriter := &gifBlockWriter{writer: gifWriter.writer}
+	lzwWriter := lzw.NewWriter(blockWriter, lzw.LSB, gifWriter.paletteBits)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += 1 {
+		rowStart := frame.PixOffset(bounds.Min.X, y)
+		if _, err := lzwWriter.Write(frame.Pix[rowStart : rowStart+gifWriter.width]); err != nil {
+			return err
+		}
+	}
+	if err := lzwWriter.Close(); err != nil {
+		return err
+	}
+	if err := blockWriter.flush(); err != nil {
+		return err
+	}
+	return gifWriter.writer.WriteByte(0x00)
+}
+
+// Write the GIF trailer and flush any buffered data. The underlying writer is not closed.
+func (gifWriter *GIFWriter) Close() error {
+	if gifWriter.closed {
+		return ErrorWriterClosed
+	}
+	gifWriter.closed = true
+	gifWriter.writer.WriteByte(0x3B)
+	return gifWriter.writer.Flush()
+}
@@ -3,6 +3,7 @@ package maze
 import (
 	"errors"
 	"fmt"
+	"hmcalister/AdventOfCode/gridrender"
 	"hmcalister/AdventOfCode/gridutils"
 	"log/slog"
 	"math"
@@ -12,23 +13,50 @@ import (
 	hashset "github.com/hmcalister/Go-DSA/set/HashSet"
 )
 
+const (
+	// The cost of turning left or right before taking a step.
+	TURN_COST int = 1000
+)
+
 type Maze struct {
-	startPosition gridutils.Coordinate
-	endPosition   gridutils.Coordinate
-	coordinateMap *hashset.HashSet[gridutils.Coordinate]
-	mazeWidth     int
-	mazeHeight    int
-	gScore        map[pathfindStepData]int
-	fScore        map[pathfindStepData]int
+	startPosition    gridutils.Coordinate
+	endPosition      gridutils.Coordinate
+	coordinateMap    *hashset.HashSet[gridutils.Coordinate]
+	terrainMap       map[gridutils.Coordinate]Terrain
+	minimumEntryCost int
+	mazeWidth        int
+	mazeHeight       int
+	gScore           map[pathfindStepData]int
+	fScore           map[pathfindStepData]int
 }
 
+// Create a new maze using the default terrain types.
 func NewMaze(mazeStrs []string) Maze {
+	return NewMazeWithTerrain(mazeStrs, DefaultTerrainTypes)
+}
+
+// Create a new maze, parsing open cells using the given terrain types.
+//
+// The start and end runes mark the start and end positions, and take the terrain associated with those runes (if any).
+// Runes that do not match any terrain type are treated as walls.
+func NewMazeWithTerrain(mazeStrs []string, terrainTypes []Terrain) Maze {
 	maze := Maze{
-		coordinateMap: hashset.New[gridutils.Coordinate](),
-		mazeWidth:     len(mazeStrs[0]),
-		mazeHeight:    len(mazeStrs),
-		gScore:        make(map[pathfindStepData]int),
-		fScore:        make(map[pathfindStepData]int),
+		coordinateMap:    hashset.New[gridutils.Coordinate](),
+		terrainMap:       make(map[gridutils.Coordinate]Terrain),
+		minimumEntryCost: math.MaxInt,
+		mazeWidth:        len(mazeStrs[0]),
+		mazeHeight:       len(mazeStrs),
+		gScore:           make(map[pathfindStepData]int),
+		fScore:           make(map[pathfindStepData]int),
+	}
+
+	terrainByRune := make(map[rune]Terrain)
+	for _, terrain := range terrainTypes {
+		if terrain.EntryCost < 0 {
+			slog.Error("terrain has negative entry cost, treating as zero", "terrain", terrain)
+			terrain.EntryCost = 0
+		}
+		terrainByRune[terrain.Rune] = terrain
 	}
 
 	for y, line := range mazeStrs {
@@ -36,20 +64,34 @@ func NewMaze(mazeStrs []string) Maze {
 		for x, cell := range line {
 			c := gridutils.Coordinate{X: x, Y: y}
 			switch cell {
-			case EMPTY_RUNE:
-				slog.Debug("found empty cell", "coordinate", c)
-				maze.coordinateMap.Add(c)
 			case START_RUNE:
 				slog.Debug("found start", "coordinate", c)
-				maze.coordinateMap.Add(c)
 				maze.startPosition = c
 			case END_RUNE:
 				slog.Debug("found end", "coordinate", c)
-				maze.coordinateMap.Add(c)
 				maze.endPosition = c
+			case WALL_RUNE:
+				continue
+			}
+
+			terrain, ok := terrainByRune[cell]
+			if !ok {
+				if cell != START_RUNE && cell != END_RUNE {
+					continue
+				}
+				terrain = emptyTerrain
 			}
+			slog.Debug("found open cell", "coordinate", c, "terrain", terrain)
+			maze.coordinateMap.Add(c)
+			if terrain != emptyTerrain {
+				maze.terrainMap[c] = terrain
+			}
+			maze.minimumEntryCost = min(maze.minimumEntryCost, terrain.EntryCost)
 		}
 	}
+	if maze.minimumEntryCost == math.MaxInt {
+		maze.minimumEntryCost = 0
+	}
 
 	return maze
 }
@@ -65,7 +107,7 @@ func (maze Maze) heuristic(step pathfindStepData) int {
 		deltaY *= -1
 	}
 
-	return deltaX + deltaY
+	return maze.minimumEntryCost * (deltaX + deltaY)
 }
 
 func (maze Maze) getGScore(step pathfindStepData) int {
@@ -89,13 +131,13 @@ func (maze Maze) expandStepSingleOptimalPath(
 ) {
 	stepGScore := maze.getGScore(step)
 
-	forwardCoord := step.position.Step(step.incomingDirection)
-	if maze.coordinateMap.Contains(forwardCoord) {
+	forwardCoord, forwardCost, forwardOk := maze.tryStep(step.position, step.incomingDirection)
+	if forwardOk {
 		forwardStep := pathfindStepData{
 			position:          forwardCoord,
 			incomingDirection: step.incomingDirection,
 		}
-		forwardGScoreViaCurrent := stepGScore + 1
+		forwardGScoreViaCurrent := stepGScore + forwardCost
 		forwardGScorePrior := maze.getGScore(forwardStep)
 		if forwardGScoreViaCurrent < forwardGScorePrior {
 			slog.Debug("expanded better path to forward neighbor", "current step", step, "forward step", forwardStep, "forward g", forwardGScoreViaCurrent)
@@ -111,13 +153,13 @@ func (maze Maze) expandStepSingleOptimalPath(
 	}
 
 	leftDirection := step.incomingDirection.RotateLeft()
-	leftCoord := step.position.Step(leftDirection)
-	if maze.coordinateMap.Contains(leftCoord) {
+	leftCoord, leftCost, leftOk := maze.tryStep(step.position, leftDirection)
+	if leftOk {
 		leftStep := pathfindStepData{
 			position:          leftCoord,
 			incomingDirection: leftDirection,
 		}
-		leftGScoreViaCurrent := stepGScore + 1001
+		leftGScoreViaCurrent := stepGScore + TURN_COST + leftCost
 		leftGScorePrior := maze.getGScore(leftStep)
 		if leftGScoreViaCurrent < leftGScorePrior {
 			slog.Debug("expanded better path to left neighbor", "current step", step, "left step", leftStep, "left g", leftGScoreViaCurrent)
@@ -133,13 +175,13 @@ func (maze Maze) expandStepSingleOptimalPath(
 	}
 
 	rightDirection := step.incomingDirection.RotateRight()
-	rightCoord := step.position.Step(rightDirection)
-	if maze.coordinateMap.Contains(rightCoord) {
+	rightCoord, rightCost, rightOk := maze.tryStep(step.position, rightDirection)
+	if rightOk {
 		rightStep := pathfindStepData{
 			position:          rightCoord,
 			incomingDirection: rightDirection,
 		}
-		rightGScoreViaCurrent := stepGScore + 1001
+		rightGScoreViaCurrent := stepGScore + TURN_COST + rightCost
 		rightGScorePrior := maze.getGScore(rightStep)
 		if rightGScoreViaCurrent < rightGScorePrior {
 			slog.Debug("expanded better path to right neighbor", "current step", step, "right step", rightStep, "right g", rightGScoreViaCurrent)
@@ -162,13 +204,13 @@ func (maze Maze) expandStepAllOptimalPaths(
 ) {
 	stepGScore := maze.getGScore(step)
 
-	forwardCoord := step.position.Step(step.incomingDirection)
-	if maze.coordinateMap.Contains(forwardCoord) {
+	forwardCoord, forwardCost, forwardOk := maze.tryStep(step.position, step.incomingDirection)
+	if forwardOk {
 		forwardStep := pathfindStepData{
 			position:          forwardCoord,
 			incomingDirection: step.incomingDirection,
 		}
-		forwardGScoreViaCurrent := stepGScore + 1
+		forwardGScoreViaCurrent := stepGScore + forwardCost
 		forwardGScorePrior := maze.getGScore(forwardStep)
 		if forwardGScoreViaCurrent == forwardGScorePrior {
 			// append the paths if we are JUST AS GOOD
@@ -189,13 +231,13 @@ func (maze Maze) expandStepAllOptimalPaths(
 	}
 
 	leftDirection := step.incomingDirection.RotateLeft()
-	leftCoord := step.position.Step(leftDirection)
-	if maze.coordinateMap.Contains(leftCoord) {
+	leftCoord, leftCost, leftOk := maze.tryStep(step.position, leftDirection)
+	if leftOk {
 		leftStep := pathfindStepData{
 			position:          leftCoord,
 			incomingDirection: leftDirection,
 		}
-		leftGScoreViaCurrent := stepGScore + 1001
+		leftGScoreViaCurrent := stepGScore + TURN_COST + leftCost
 		leftGScorePrior := maze.getGScore(leftStep)
 		if leftGScoreViaCurrent == leftGScorePrior {
 			// append the paths if we are JUST AS GOOD
@@ -216,13 +258,13 @@ func (maze Maze) expandStepAllOptimalPaths(
 	}
 
 	rightDirection := step.incomingDirection.RotateRight()
-	rightCoord := step.position.Step(rightDirection)
-	if maze.coordinateMap.Contains(rightCoord) {
+	rightCoord, rightCost, rightOk := maze.tryStep(step.position, rightDirection)
+	if rightOk {
 		rightStep := pathfindStepData{
 			position:          rightCoord,
 			incomingDirection: rightDirection,
 		}
-		rightGScoreViaCurrent := stepGScore + 1001
+		rightGScoreViaCurrent := stepGScore + TURN_COST + rightCost
 		rightGScorePrior := maze.getGScore(rightStep)
 		if rightGScoreViaCurrent == rightGScorePrior {
 			// append the paths if we are JUST AS GOOD
@@ -251,58 +293,20 @@ func (maze Maze) printOptimalPath(finalStep pathfindStepData, cameFrom map[pathf
 		reconstructedStep = cameFrom[reconstructedStep]
 	}
 
-	mazeString := make([]rune, maze.mazeHeight*(maze.mazeWidth+1))
-	mazeStringIndex := 0
-	for y := 0; y < maze.mazeHeight; y += 1 {
-		for x := 0; x < maze.mazeWidth; x += 1 {
-			c := gridutils.Coordinate{X: x, Y: y}
-			if c == maze.endPosition {
-				mazeString[mazeStringIndex] = END_RUNE
-			} else if c == maze.startPosition {
-				mazeString[mazeStringIndex] = START_RUNE
-			} else if reconstructedPathStep, ok := completePathSteps[c]; ok {
-				switch reconstructedPathStep.incomingDirection {
-				case gridutils.DIRECTION_UP:
-					mazeString[mazeStringIndex] = '^'
-				case gridutils.DIRECTION_RIGHT:
-					mazeString[mazeStringIndex] = '>'
-				case gridutils.DIRECTION_DOWN:
-					mazeString[mazeStringIndex] = 'v'
-				case gridutils.DIRECTION_LEFT:
-					mazeString[mazeStringIndex] = '<'
-				}
-			} else if !maze.coordinateMap.Contains(c) {
-				mazeString[mazeStringIndex] = WALL_RUNE
-			} else {
-				mazeString[mazeStringIndex] = ' '
-			}
-			mazeStringIndex += 1
+	pathOverlay := gridrender.EntityOverlay{}
+	for position, pathStep := range completePathSteps {
+		if position == maze.endPosition {
+			continue
 		}
-		mazeString[mazeStringIndex] = '\n'
-		mazeStringIndex += 1
+		stepStyle := PathStyle
+		stepStyle.Rune = directionRunes[pathStep.incomingDirection]
+		pathOverlay.Add(position, stepStyle)
 	}
-	fmt.Println(string(mazeString))
+	fmt.Println(gridrender.RenderASCII(maze.Canvas(pathOverlay)))
 }
 
 func (maze Maze) printCoordinatesOnAnyOptimalPath(coordinatesOnAnyOptimalPath *hashset.HashSet[gridutils.Coordinate]) {
-	mazeString := make([]rune, maze.mazeHeight*(maze.mazeWidth+1))
-	mazeStringIndex := 0
-	for y := 0; y < maze.mazeHeight; y += 1 {
-		for x := 0; x < maze.mazeWidth; x += 1 {
-			c := gridutils.Coordinate{X: x, Y: y}
-			if coordinatesOnAnyOptimalPath.Contains(c) {
-				mazeString[mazeStringIndex] = 'O'
-			} else if !maze.coordinateMap.Contains(c) {
-				mazeString[mazeStringIndex] = WALL_RUNE
-			} else {
-				mazeString[mazeStringIndex] = ' '
-			}
-			mazeStringIndex += 1
-		}
-		mazeString[mazeStringIndex] = '\n'
-		mazeStringIndex += 1
-	}
-	fmt.Println(string(mazeString))
+	fmt.Println(gridrender.RenderASCII(maze.Canvas(gridrender.SetOverlay{Set: coordinatesOnAnyOptimalPath, Style: PathStyle})))
 }
 
 // Find the optimal path using A* pathfinding
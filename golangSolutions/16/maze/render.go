@@ -0,0 +1,72 @@
+package maze
+
+import (
+	"hmcalister/AdventOfCode/gridrender"
+	"hmcalister/AdventOfCode/gridutils"
+	"image/color"
+)
+
+var (
+	wallStyle  = gridrender.CellStyle{Rune: WALL_RUNE, ANSIStyle: "90", Color: color.RGBA{40, 40, 40, 255}}
+	startStyle = gridrender.CellStyle{Rune: START_RUNE, ANSIStyle: "1;34", Color: color.RGBA{60, 120, 220, 255}}
+	endStyle   = gridrender.CellStyle{Rune: END_RUNE, ANSIStyle: "1;31", Color: color.RGBA{220, 60, 60, 255}}
+
+	// Styles of the default terrain types. Other terrain is drawn in the fallback style, keeping its rune.
+	terrainStyles = map[rune]gridrender.CellStyle{
+		EMPTY_RUNE:          {ANSIStyle: "2", Color: color.RGBA{240, 240, 240, 255}},
+		SWAMP_RUNE:          {ANSIStyle: "36", Color: color.RGBA{110, 160, 140, 255}},
+		CONVEYOR_UP_RUNE:    {ANSIStyle: "35", Color: color.RGBA{180, 140, 220, 255}},
+		CONVEYOR_RIGHT_RUNE: {ANSIStyle: "35", Color: color.RGBA{180, 140, 220, 255}},
+		CONVEYOR_DOWN_RUNE:  {ANSIStyle: "35", Color: color.RGBA{180, 140, 220, 255}},
+		CONVEYOR_LEFT_RUNE:  {ANSIStyle: "35", Color: color.RGBA{180, 140, 220, 255}},
+	}
+	fallbackTerrainStyle = gridrender.CellStyle{Color: color.RGBA{200, 200, 200, 255}}
+
+	// Arrows for the direction a path entered each cell
+	directionRunes = map[gridutils.Direction]rune{
+		gridutils.DIRECTION_UP:    '^',
+		gridutils.DIRECTION_RIGHT: '>',
+		gridutils.DIRECTION_DOWN:  'v',
+		gridutils.DIRECTION_LEFT:  '<',
+	}
+
+	// The style used to draw paths through the maze
+	PathStyle = gridrender.CellStyle{Rune: 'O', ANSIStyle: "1;32", Color: color.RGBA{80, 200, 80, 255}}
+)
+
+func (maze Maze) Width() int {
+	return maze.mazeWidth
+}
+
+func (maze Maze) Height() int {
+	return maze.mazeHeight
+}
+
+// Get the style of a cell when rendering the maze, allowing the maze to be used as a gridrender.Grid
+func (maze Maze) CellStyle(c gridutils.Coordinate) gridrender.CellStyle {
+	switch {
+	case c == maze.endPosition:
+		return endStyle
+	case c == maze.startPosition:
+		return startStyle
+	case !maze.coordinateMap.Contains(c):
+		return wallStyle
+	}
+
+	terrain := maze.terrainAt(c)
+	style, ok := terrainStyles[terrain.Rune]
+	if !ok {
+		style = fallbackTerrainStyle
+	}
+	style.Rune = terrain.Rune
+	// Empty cells are left blank so paths stand out
+	if terrain == emptyTerrain {
+		style.Rune = ' '
+	}
+	return style
+}
+
+// Get a canvas of the maze with the given overlays drawn on top, ready to render in any format
+func (maze Maze) Canvas(overlays ...gridrender.Overlay) *gridrender.Canvas {
+	return gridrender.NewCanvas(maze, overlays...)
+}
@@ -1,8 +1,13 @@
 package maze
 
 const (
-	WALL_RUNE  rune = '#'
-	START_RUNE rune = 'S'
-	END_RUNE   rune = 'E'
-	EMPTY_RUNE rune = '.'
+	WALL_RUNE           rune = '#'
+	START_RUNE          rune = 'S'
+	END_RUNE            rune = 'E'
+	EMPTY_RUNE          rune = '.'
+	SWAMP_RUNE          rune = '~'
+	CONVEYOR_UP_RUNE    rune = '^'
+	CONVEYOR_RIGHT_RUNE rune = '>'
+	CONVEYOR_DOWN_RUNE  rune = 'v'
+	CONVEYOR_LEFT_RUNE  rune = '<'
 )
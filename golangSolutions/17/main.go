@@ -7,25 +7,45 @@ import (
 	"fmt"
 	"hmcalister/AdventOfCode/tribitemulator"
 	"log/slog"
+	"math/big"
 	"os"
 	"runtime/pprof"
-	"slices"
 	"strconv"
 	"strings"
 	"time"
-
-	arrayqueue "github.com/hmcalister/Go-DSA/queue/ArrayQueue"
 )
 
 const (
 	CPU_PROFILE_FILEPATH string = "profile"
 )
 
+var (
+	printDisassembly bool
+	assemblyFilePath string
+	targetOutputStr  string
+	symbolicSearch   bool
+	printEquations   bool
+	maxSteps         int
+)
+
+const (
+	// The number of characters of each equation printed with -printEquations
+	PRINTED_EQUATION_LENGTH int = 200
+)
+
 func main() {
 	debugFlag := flag.Bool("debug", false, "Debug Flag")
 	inputFilePath := flag.String("inputFile", "puzzleInput", "Path to input file.")
 	selectedPart := flag.Int("part", 0, "Part to execute. Must be 1 or 2.")
 	profile := flag.Bool("profile", false, "Flag to profile program")
+	flag.BoolVar(&printDisassembly, "disassemble", false, "Print the disassembled program before executing.")
+	replFlag := flag.Bool("repl", false, "Start an interactive debugger over the input program instead of running a part.")
+	flag.StringVar(&assemblyFilePath, "assemblyFile", "", "Path to a file of tribit assembly. If given, replaces the program from the input file.")
+	flag.StringVar(&targetOutputStr, "targetOutput", "", "Comma separated output to find initial values of register A for in part 2. Defaults to the program itself.")
+	flag.BoolVar(&symbolicSearch, "symbolic", false, "Search for initial values of register A in part 2 by symbolic execution, rather than relying on the program shifting A by 3 per output.")
+	flag.BoolVar(&printEquations, "printEquations", false, "Print the bit equations found by symbolic execution in part 2. Implies -symbolic.")
+	flag.IntVar(&maxSteps, "maxSteps", tribitemulator.DEFAULT_MAX_STEPS, "Maximum number of instructions a program may execute before failing. Non-positive values remove the limit.")
+	benchmarkRuns := flag.Int("benchmark", 0, "If positive, time this many executions of the input program by each emulator instead of running a part.")
 	flag.Parse()
 	if *profile {
 		f, err := os.Create(CPU_PROFILE_FILEPATH)
@@ -53,6 +73,21 @@ func main() {
 		os.Exit(1)
 	}
 
+	if *replFlag {
+		program, registerA, registerB, registerC := parseInputToProgramAndRegisters(fileScanner)
+		RunREPL(program, registerA, registerB, registerC)
+		return
+	}
+
+	if *benchmarkRuns > 0 {
+		program, registerA, registerB, registerC := parseInputToProgramAndRegisters(fileScanner)
+		if err := benchmarkExecution(program, registerA, registerB, registerC, *benchmarkRuns); err != nil {
+			slog.Error("error encountered during benchmark", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	var result int
 	computationStartTime := time.Now()
 	switch *selectedPart {
@@ -140,14 +175,85 @@ func parseInputToProgramAndRegisters(fileScanner *bufio.Scanner) ([]int, int, in
 		program[index] = value
 	}
 
+	if assemblyFilePath != "" {
+		assemblyBytes, err := os.ReadFile(assemblyFilePath)
+		if err != nil {
+			slog.Error("could not read assembly file", "assembly file", assemblyFilePath, "error", err)
+			os.Exit(1)
+		}
+		program, err = tribitemulator.Assemble(strings.Split(string(assemblyBytes), "\n"))
+		if err != nil {
+			slog.Error("could not assemble program", "assembly file", assemblyFilePath, "error", err)
+			os.Exit(1)
+		}
+	}
+
+	if printDisassembly {
+		for _, line := range tribitemulator.Disassemble(program) {
+			fmt.Println(line)
+		}
+		fmt.Println()
+	}
+
 	return program, registerA, registerB, registerC
 }
 
+// Time executing the program with numRuns consecutive initial values of register A, starting from registerA,
+// using the interpreted emulator, the uint64 emulator, and the compiled program.
+func benchmarkExecution(program []int, registerA, registerB, registerC int, numRuns int) error {
+	compiledProgram, err := tribitemulator.Compile(program)
+	if err != nil {
+		return err
+	}
+
+	executors := []struct {
+		name    string
+		execute func(initialA int) ([]int, error)
+	}{
+		{"interpreter", func(initialA int) ([]int, error) {
+			emulator := tribitemulator.NewTribitEmulator(initialA, registerB, registerC)
+			emulator.SetMaxSteps(maxSteps)
+			return emulator.ExecuteProgram(program)
+		}},
+		{"uint64 interpreter", func(initialA int) ([]int, error) {
+			emulator := tribitemulator.NewUint64TribitEmulator(uint64(initialA), uint64(registerB), uint64(registerC))
+			emulator.SetMaxSteps(maxSteps)
+			return emulator.ExecuteProgram(program)
+		}},
+		{"compiled", func(initialA int) ([]int, error) {
+			return compiledProgram(initialA, registerB, registerC)
+		}},
+	}
+
+	for _, executor := range executors {
+		// Sum the outputs so the executions cannot be optimised away, and so executors can be compared
+		outputChecksum := 0
+		startTime := time.Now()
+		for run := range numRuns {
+			output, err := executor.execute(registerA + run)
+			if err != nil {
+				return fmt.Errorf("%s: %w", executor.name, err)
+			}
+			for _, value := range output {
+				outputChecksum += value
+			}
+		}
+		elapsed := time.Since(startTime)
+		fmt.Printf("%-20s %12d ns/op  (output checksum %d)\n", executor.name, elapsed.Nanoseconds()/int64(numRuns), outputChecksum)
+		slog.Info("benchmark completed", "executor", executor.name, "runs", numRuns, "elapsed (ns)", elapsed.Nanoseconds(), "output checksum", outputChecksum)
+	}
+	return nil
+}
+
 func Part01(fileScanner *bufio.Scanner) (int, error) {
 	program, registerA, registerB, registerC := parseInputToProgramAndRegisters(fileScanner)
 	slog.Debug("parsed input", "program", program, "registerA", registerA, "registerB", registerB, "registerC", registerC)
 	emulator := tribitemulator.NewTribitEmulator(registerA, registerB, registerC)
-	output := emulator.ExecuteProgram(program)
+	emulator.SetMaxSteps(maxSteps)
+	output, err := emulator.ExecuteProgram(program)
+	if err != nil {
+		return -1, err
+	}
 	slog.Info("program output", "output", output)
 
 	if len(output) > 0 {
@@ -165,27 +271,51 @@ func Part02(fileScanner *bufio.Scanner) (int, error) {
 	program, registerA, registerB, registerC := parseInputToProgramAndRegisters(fileScanner)
 	slog.Debug("parsed input", "program", program, "registerA", registerA, "registerB", registerB, "registerC", registerC)
 
-	type registerSearchData struct {
-		initialAValue         int
-		nextSuffixMatchLength int
-	}
-	registerSearchQueue := arrayqueue.New[registerSearchData]()
-	registerSearchQueue.Add(registerSearchData{0, 1})
-
-	for registerSearchQueue.Size() > 0 {
-		currentRegisterSearch, _ := registerSearchQueue.Remove()
-		for initialAValue := currentRegisterSearch.initialAValue; initialAValue < currentRegisterSearch.initialAValue+8; initialAValue += 1 {
-			emulator := tribitemulator.NewTribitEmulator(initialAValue, registerB, registerC)
-			output := emulator.ExecuteProgram(program)
-			slog.Debug("register search loop", "initial a value", initialAValue, "output", output, "program prefix", program[len(program)-currentRegisterSearch.nextSuffixMatchLength:])
-			if slices.Equal(output, program[len(program)-currentRegisterSearch.nextSuffixMatchLength:]) {
-				if currentRegisterSearch.nextSuffixMatchLength == len(program) {
-					return initialAValue, nil
-				}
-				registerSearchQueue.Add(registerSearchData{initialAValue * 8, currentRegisterSearch.nextSuffixMatchLength + 1})
+	targetOutput := program
+	if targetOutputStr != "" {
+		targetOutput = make([]int, 0)
+		for _, valueStr := range strings.Split(targetOutputStr, ",") {
+			value, err := strconv.Atoi(strings.TrimSpace(valueStr))
+			if err != nil {
+				return -1, fmt.Errorf("could not parse target output value %q to integer", valueStr)
 			}
+			targetOutput = append(targetOutput, value)
 		}
 	}
 
-	return -1, errors.New("did not find any matching prefix value")
+	var solutions []*big.Int
+	var err error
+	if symbolicSearch || printEquations {
+		emulator := tribitemulator.NewSymbolicTribitEmulator(3*len(targetOutput), registerB, registerC)
+		system, err := emulator.ExecuteProgram(program, targetOutput)
+		if err != nil {
+			return -1, err
+		}
+		if printEquations {
+			for _, equation := range system.Equations() {
+				fmt.Println(system.FormatEquation(equation, PRINTED_EQUATION_LENGTH))
+			}
+			fmt.Println()
+		}
+		solutions, err = system.Solve(0)
+		if err != nil {
+			return -1, err
+		}
+	} else {
+		// Registers B and C are overwritten before use by any program FindInitialA accepts, so only A is searched for
+		solutions, err = tribitemulator.FindInitialA(program, targetOutput)
+		if err != nil {
+			return -1, err
+		}
+	}
+	slog.Info("found initial values of register A", "number of solutions", len(solutions), "solutions", solutions)
+	for _, solution := range solutions {
+		fmt.Println(solution)
+	}
+
+	// Solutions are sorted, so the first is the smallest
+	if !solutions[0].IsInt64() {
+		return -1, errors.New("initial value of register A found, but is too large to return as an int")
+	}
+	return int(solutions[0].Int64()), nil
 }
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"hmcalister/AdventOfCode/tribitemulator"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	REPL_HELP string = `commands:
+  step [n]          execute the next n instructions (default 1)
+  continue          execute until halted, or a breakpoint or watchpoint is hit
+  break <ip>        stop before executing the instruction at ip
+  delete <ip>       remove the breakpoint at ip
+  watch <A|B|C>     stop after any instruction that changes the register
+  unwatch <A|B|C>   remove the watchpoint on the register
+  registers         print the registers and instruction pointer
+  output            print the program output so far
+  list              print the disassembled program, marking the instruction pointer
+  trace <file>      write the execution trace so far to file as NDJSON
+  reset             reload the program and initial registers
+  help              print this message
+  quit              exit the debugger`
+)
+
+var (
+	replRegisterNames = map[string]tribitemulator.Register{
+		"A": tribitemulator.REGISTER_A,
+		"B": tribitemulator.REGISTER_B,
+		"C": tribitemulator.REGISTER_C,
+	}
+)
+
+func printEmulatorState(writer io.Writer, emulator *tribitemulator.TribitEmulator) {
+	fmt.Fprintf(writer, "ip=%d A=%d B=%d C=%d\n",
+		emulator.InstructionPointer(),
+		emulator.GetRegister(tribitemulator.REGISTER_A),
+		emulator.GetRegister(tribitemulator.REGISTER_B),
+		emulator.GetRegister(tribitemulator.REGISTER_C),
+	)
+	if emulator.IsHalted() {
+		fmt.Fprintln(writer, "program halted")
+	} else {
+		fmt.Fprintf(writer, "next: %s\n", tribitemulator.DisassembleInstruction(emulator.Program(), emulator.InstructionPointer()))
+	}
+}
+
+// Start an interactive debugger over the program and registers, reading commands from stdin.
+func RunREPL(program []int, registerA, registerB, registerC int) {
+	newEmulator := func() *tribitemulator.TribitEmulator {
+		emulator := tribitemulator.NewTribitEmulator(registerA, registerB, registerC)
+		emulator.SetMaxSteps(maxSteps)
+		emulator.LoadProgram(program)
+		emulator.EnableTrace()
+		return &emulator
+	}
+	emulator := newEmulator()
+
+	fmt.Println(REPL_HELP)
+	printEmulatorState(os.Stdout, emulator)
+	keyboardScanner := bufio.NewScanner(os.Stdin)
+	for {
+		fmt.Print("(tribit) ")
+		if !keyboardScanner.Scan() {
+			return
+		}
+		commandFields := strings.Fields(keyboardScanner.Text())
+		if len(commandFields) == 0 {
+			continue
+		}
+
+		switch commandFields[0] {
+		case "step", "s":
+			numSteps := 1
+			if len(commandFields) > 1 {
+				var err error
+				if numSteps, err = strconv.Atoi(commandFields[1]); err != nil {
+					fmt.Println("step count must be an integer")
+					continue
+				}
+			}
+			for range numSteps {
+				halted, err := emulator.Step()
+				if err != nil {
+					fmt.Println("could not execute instruction:", err)
+					break
+				}
+				if halted {
+					break
+				}
+			}
+			printEmulatorState(os.Stdout, emulator)
+		case "continue", "c":
+			stopReason, err := emulator.Continue()
+			fmt.Println("stopped:", stopReason)
+			if err != nil {
+				fmt.Println("could not execute instruction:", err)
+			}
+			printEmulatorState(os.Stdout, emulator)
+		case "break", "b", "delete", "d":
+			if len(commandFields) != 2 {
+				fmt.Println("expected an instruction pointer")
+				continue
+			}
+			instructionPointer, err := strconv.Atoi(commandFields[1])
+			if err != nil {
+				fmt.Println("instruction pointer must be an integer")
+				continue
+			}
+			if commandFields[0] == "break" || commandFields[0] == "b" {
+				emulator.AddBreakpoint(instructionPointer)
+			} else {
+				emulator.RemoveBreakpoint(instructionPointer)
+			}
+		case "watch", "w", "unwatch":
+			if len(commandFields) != 2 {
+				fmt.Println("expected a register name")
+				continue
+			}
+			register, ok := replRegisterNames[strings.ToUpper(commandFields[1])]
+			if !ok {
+				fmt.Println("register must be one of A, B, C")
+				continue
+			}
+			if commandFields[0] == "unwatch" {
+				emulator.RemoveWatchpoint(register)
+			} else {
+				emulator.AddWatchpoint(register)
+			}
+		case "registers", "r":
+			printEmulatorState(os.Stdout, emulator)
+		case "output", "o":
+			fmt.Println(emulator.Output())
+		case "list", "l":
+			currentProgram := emulator.Program()
+			for address := 0; address < len(currentProgram); address += 2 {
+				marker := "  "
+				if address == emulator.InstructionPointer() {
+					marker = "=>"
+				}
+				fmt.Printf("%s %3d  %s\n", marker, address, tribitemulator.DisassembleInstruction(currentProgram, address))
+			}
+		case "trace", "t":
+			if len(commandFields) != 2 {
+				fmt.Println("expected a file path")
+				continue
+			}
+			traceFile, err := os.Create(commandFields[1])
+			if err != nil {
+				fmt.Println("could not create trace file:", err)
+				continue
+			}
+			err = emulator.WriteTraceNDJSON(traceFile)
+			traceFile.Close()
+			if err != nil {
+				fmt.Println("could not write trace file:", err)
+			}
+		case "reset":
+			emulator = newEmulator()
+			printEmulatorState(os.Stdout, emulator)
+		case "help", "h":
+			fmt.Println(REPL_HELP)
+		case "quit", "q", "exit":
+			return
+		default:
+			fmt.Println("unknown command, type help for a list of commands")
+		}
+	}
+}
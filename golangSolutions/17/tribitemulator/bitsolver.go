@@ -0,0 +1,338 @@
+package tribitemulator
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// A reference to a boolean expression within a BitEquationSystem
+type BitExpression int
+
+const (
+	BIT_EXPRESSION_FALSE BitExpression = 0
+	BIT_EXPRESSION_TRUE  BitExpression = 1
+
+	// The minimum variable of an expression that depends on no variables
+	NO_VARIABLE int = math.MaxInt
+)
+
+type bitOperator int
+
+const (
+	BIT_OPERATOR_CONSTANT bitOperator = iota
+	BIT_OPERATOR_VARIABLE
+	BIT_OPERATOR_NOT
+	BIT_OPERATOR_AND
+	BIT_OPERATOR_OR
+	BIT_OPERATOR_XOR
+)
+
+// A single node in the expression graph. Nodes are hash-consed, so identical subexpressions are shared.
+type bitExpressionNode struct {
+	operator bitOperator
+
+	// The operands of the node. Variable nodes store the variable index in left, constant nodes store 0 or 1.
+	left  int
+	right int
+}
+
+// An equation requiring an expression to take a specific value.
+type BitEquation struct {
+	Expression  BitExpression
+	Value       bool
+	Description string
+}
+
+// A system of boolean equations over a number of variables, built from shared expressions.
+//
+// Variable i is bit i of the unknown value being solved for, so a solution is read as a (non-negative) integer.
+type BitEquationSystem struct {
+	numVariables int
+	nodes        []bitExpressionNode
+	minVariables []int
+	nodeLookup   map[bitExpressionNode]BitExpression
+	equations    []BitEquation
+}
+
+func NewBitEquationSystem(numVariables int) *BitEquationSystem {
+	system := &BitEquationSystem{
+		numVariables: numVariables,
+		nodes:        make([]bitExpressionNode, 0),
+		minVariables: make([]int, 0),
+		nodeLookup:   make(map[bitExpressionNode]BitExpression),
+		equations:    make([]BitEquation, 0),
+	}
+	system.addNode(bitExpressionNode{BIT_OPERATOR_CONSTANT, 0, 0}, NO_VARIABLE)
+	system.addNode(bitExpressionNode{BIT_OPERATOR_CONSTANT, 1, 0}, NO_VARIABLE)
+	return system
+}
+
+func (system *BitEquationSystem) addNode(node bitExpressionNode, minVariable int) BitExpression {
+	if expression, ok := system.nodeLookup[node]; ok {
+		return expression
+	}
+	expression := BitExpression(len(system.nodes))
+	system.nodes = append(system.nodes, node)
+	system.minVariables = append(system.minVariables, minVariable)
+	system.nodeLookup[node] = expression
+	return expression
+}
+
+func (system *BitEquationSystem) NumVariables() int {
+	return system.numVariables
+}
+
+// Get the number of distinct expressions in the system, including constants and variables
+func (system *BitEquationSystem) NumExpressions() int {
+	return len(system.nodes)
+}
+
+func (system *BitEquationSystem) Equations() []BitEquation {
+	return system.equations
+}
+
+func (system *BitEquationSystem) AddEquation(expression BitExpression, value bool, description string) {
+	system.equations = append(system.equations, BitEquation{expression, value, description})
+}
+
+// --------------------------------------------------------------------------------
+// Expression construction
+//
+// Constructors fold constants and trivial identities, so expressions that do not depend on any variable are always constants.
+
+func (system *BitEquationSystem) Constant(value bool) BitExpression {
+	if value {
+		return BIT_EXPRESSION_TRUE
+	}
+	return BIT_EXPRESSION_FALSE
+}
+
+func (system *BitEquationSystem) Variable(index int) BitExpression {
+	return system.addNode(bitExpressionNode{BIT_OPERATOR_VARIABLE, index, 0}, index)
+}
+
+func (system *BitEquationSystem) isNegationOf(x, y BitExpression) bool {
+	xNode, yNode := system.nodes[x], system.nodes[y]
+	return (xNode.operator == BIT_OPERATOR_NOT && BitExpression(xNode.left) == y) ||
+		(yNode.operator == BIT_OPERATOR_NOT && BitExpression(yNode.left) == x)
+}
+
+// Create a node for a commutative operator, ordering the operands so equivalent expressions are shared
+func (system *BitEquationSystem) commutativeNode(operator bitOperator, x, y BitExpression) BitExpression {
+	if x > y {
+		x, y = y, x
+	}
+	return system.addNode(bitExpressionNode{operator, int(x), int(y)}, min(system.minVariables[x], system.minVariables[y]))
+}
+
+func (system *BitEquationSystem) Not(x BitExpression) BitExpression {
+	switch {
+	case x == BIT_EXPRESSION_FALSE:
+		return BIT_EXPRESSION_TRUE
+	case x == BIT_EXPRESSION_TRUE:
+		return BIT_EXPRESSION_FALSE
+	case system.nodes[x].operator == BIT_OPERATOR_NOT:
+		return BitExpression(system.nodes[x].left)
+	}
+	return system.addNode(bitExpressionNode{BIT_OPERATOR_NOT, int(x), 0}, system.minVariables[x])
+}
+
+func (system *BitEquationSystem) And(x, y BitExpression) BitExpression {
+	switch {
+	case x == BIT_EXPRESSION_FALSE || y == BIT_EXPRESSION_FALSE:
+		return BIT_EXPRESSION_FALSE
+	case x == BIT_EXPRESSION_TRUE:
+		return y
+	case y == BIT_EXPRESSION_TRUE || x == y:
+		return x
+	case system.isNegationOf(x, y):
+		return BIT_EXPRESSION_FALSE
+	}
+	return system.commutativeNode(BIT_OPERATOR_AND, x, y)
+}
+
+func (system *BitEquationSystem) Or(x, y BitExpression) BitExpression {
+	switch {
+	case x == BIT_EXPRESSION_TRUE || y == BIT_EXPRESSION_TRUE:
+		return BIT_EXPRESSION_TRUE
+	case x == BIT_EXPRESSION_FALSE:
+		return y
+	case y == BIT_EXPRESSION_FALSE || x == y:
+		return x
+	case system.isNegationOf(x, y):
+		return BIT_EXPRESSION_TRUE
+	}
+	return system.commutativeNode(BIT_OPERATOR_OR, x, y)
+}
+
+func (system *BitEquationSystem) Xor(x, y BitExpression) BitExpression {
+	switch {
+	case x == BIT_EXPRESSION_FALSE:
+		return y
+	case y == BIT_EXPRESSION_FALSE:
+		return x
+	case x == BIT_EXPRESSION_TRUE:
+		return system.Not(y)
+	case y == BIT_EXPRESSION_TRUE:
+		return system.Not(x)
+	case x == y:
+		return BIT_EXPRESSION_FALSE
+	case system.isNegationOf(x, y):
+		return BIT_EXPRESSION_TRUE
+	}
+	return system.commutativeNode(BIT_OPERATOR_XOR, x, y)
+}
+
+// Select x if the selector is true, otherwise y
+func (system *BitEquationSystem) Mux(selector, x, y BitExpression) BitExpression {
+	if x == y {
+		return x
+	}
+	return system.Or(system.And(selector, x), system.And(system.Not(selector), y))
+}
+
+// --------------------------------------------------------------------------------
+// Formatting
+
+// Format an expression using the variable names a0, a1, ... and the operators !, &, |, ^.
+//
+// Expressions can be exponentially large when written out as a tree, so the result is cut off after maxLength characters.
+func (system *BitEquationSystem) FormatExpression(expression BitExpression, maxLength int) string {
+	var builder strings.Builder
+	var write func(BitExpression)
+	write = func(expression BitExpression) {
+		if builder.Len() > maxLength {
+			return
+		}
+		node := system.nodes[expression]
+		switch node.operator {
+		case BIT_OPERATOR_CONSTANT:
+			fmt.Fprintf(&builder, "%d", node.left)
+		case BIT_OPERATOR_VARIABLE:
+			fmt.Fprintf(&builder, "a%d", node.left)
+		case BIT_OPERATOR_NOT:
+			builder.WriteString("!")
+			write(BitExpression(node.left))
+		default:
+			operatorSymbol := map[bitOperator]string{BIT_OPERATOR_AND: " & ", BIT_OPERATOR_OR: " | ", BIT_OPERATOR_XOR: " ^ "}[node.operator]
+			builder.WriteString("(")
+			write(BitExpression(node.left))
+			builder.WriteString(operatorSymbol)
+			write(BitExpression(node.right))
+			builder.WriteString(")")
+		}
+	}
+	write(expression)
+
+	if builder.Len() > maxLength {
+		return builder.String()[:maxLength] + "..."
+	}
+	return builder.String()
+}
+
+// Format an equation as "description: expression = value"
+func (system *BitEquationSystem) FormatEquation(equation BitEquation, maxLength int) string {
+	value := 0
+	if equation.Value {
+		value = 1
+	}
+	return fmt.Sprintf("%s: %s = %d", equation.Description, system.FormatExpression(equation.Expression, maxLength), value)
+}
+
+// --------------------------------------------------------------------------------
+// Solving
+
+// Find the values satisfying every equation in the system, in ascending order.
+//
+// Variables are assigned from the most significant down, trying 0 before 1, so solutions are found in ascending order.
+// Each equation is checked as soon as the lowest variable it depends on is assigned, at which point it is fully determined,
+// pruning the search as early as possible for equations (like those from tribit outputs) that depend on contiguous high bits.
+//
+// At most maxSolutions solutions are returned, or every solution if maxSolutions is not positive.
+func (system *BitEquationSystem) Solve(maxSolutions int) ([]*big.Int, error) {
+	equationsByMinVariable := make(map[int][]BitEquation)
+	for _, equation := range system.equations {
+		minVariable := system.minVariables[equation.Expression]
+		if minVariable == NO_VARIABLE {
+			if (equation.Expression == BIT_EXPRESSION_TRUE) != equation.Value {
+				return nil, fmt.Errorf("%w: equation %q can never hold", ErrorNoInitialValueOfA, equation.Description)
+			}
+			continue
+		}
+		equationsByMinVariable[minVariable] = append(equationsByMinVariable[minVariable], equation)
+	}
+
+	assignment := make([]bool, system.numVariables)
+	evaluatedGeneration := make([]int, len(system.nodes))
+	evaluatedValue := make([]bool, len(system.nodes))
+	generation := 0
+	var evaluate func(BitExpression) bool
+	evaluate = func(expression BitExpression) bool {
+		if evaluatedGeneration[expression] == generation {
+			return evaluatedValue[expression]
+		}
+		node := system.nodes[expression]
+		var value bool
+		switch node.operator {
+		case BIT_OPERATOR_CONSTANT:
+			value = node.left == 1
+		case BIT_OPERATOR_VARIABLE:
+			value = assignment[node.left]
+		case BIT_OPERATOR_NOT:
+			value = !evaluate(BitExpression(node.left))
+		case BIT_OPERATOR_AND:
+			value = evaluate(BitExpression(node.left)) && evaluate(BitExpression(node.right))
+		case BIT_OPERATOR_OR:
+			value = evaluate(BitExpression(node.left)) || evaluate(BitExpression(node.right))
+		case BIT_OPERATOR_XOR:
+			value = evaluate(BitExpression(node.left)) != evaluate(BitExpression(node.right))
+		}
+		evaluatedGeneration[expression] = generation
+		evaluatedValue[expression] = value
+		return value
+	}
+
+	solutions := make([]*big.Int, 0)
+	numSearchNodes := 0
+	var assignVariable func(variable int) bool
+	assignVariable = func(variable int) (searchComplete bool) {
+		if variable < 0 {
+			solution := new(big.Int)
+			for index, bit := range assignment {
+				if bit {
+					solution.SetBit(solution, index, 1)
+				}
+			}
+			solutions = append(solutions, solution)
+			return maxSolutions > 0 && len(solutions) >= maxSolutions
+		}
+
+		for _, value := range []bool{false, true} {
+			numSearchNodes += 1
+			assignment[variable] = value
+			generation += 1
+			satisfied := true
+			for _, equation := range equationsByMinVariable[variable] {
+				if evaluate(equation.Expression) != equation.Value {
+					satisfied = false
+					break
+				}
+			}
+			if satisfied && assignVariable(variable-1) {
+				return true
+			}
+		}
+		assignment[variable] = false
+		return false
+	}
+	assignVariable(system.numVariables - 1)
+	slog.Debug("solved bit equation system", "number of variables", system.numVariables, "number of equations", len(system.equations), "number of expressions", len(system.nodes), "search nodes", numSearchNodes, "number of solutions", len(solutions))
+
+	if len(solutions) == 0 {
+		return nil, ErrorNoInitialValueOfA
+	}
+	return solutions, nil
+}
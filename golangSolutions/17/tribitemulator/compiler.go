@@ -0,0 +1,181 @@
+package tribitemulator
+
+import (
+	"fmt"
+)
+
+// A program compiled to Go closures, executed with the given initial registers.
+//
+// Returns the output so far and ErrorStepLimitExceeded if the program does not halt within the step limit it was compiled with.
+type CompiledProgram func(registerA, registerB, registerC int) ([]int, error)
+
+type compiledState struct {
+	registerA int
+	registerB int
+	registerC int
+	output    []int
+}
+
+// A single pre-decoded instruction, which updates the state and returns the address of the next instruction
+type compiledInstruction func(state *compiledState) int
+
+// Resolve a combo operand at compile time to a function reading its value
+func compileComboOperand(operand ComboOperand, address int) (func(state *compiledState) int, error) {
+	switch operand {
+	case COMBO_OPERAND_LITERAL_0, COMBO_OPERAND_LITERAL_1, COMBO_OPERAND_LITERAL_2, COMBO_OPERAND_LITERAL_3:
+		literal := int(operand)
+		return func(state *compiledState) int { return literal }, nil
+	case COMBO_OPERAND_REGISTER_A:
+		return func(state *compiledState) int { return state.registerA }, nil
+	case COMBO_OPERAND_REGISTER_B:
+		return func(state *compiledState) int { return state.registerB }, nil
+	case COMBO_OPERAND_REGISTER_C:
+		return func(state *compiledState) int { return state.registerC }, nil
+	}
+	return nil, fmt.Errorf("%w: combo operand %d at address %d", ErrorReservedOperand, operand, address)
+}
+
+func compileInstruction(program []int, address int) (compiledInstruction, error) {
+	instruction := Instruction(program[address])
+	operand := program[address+1]
+	nextAddress := address + 2
+	if instruction < INSTRUCTION_ADV || instruction > INSTRUCTION_CDV {
+		return nil, fmt.Errorf("%w: opcode %d at address %d", ErrorInvalidOpcode, instruction, address)
+	}
+
+	// Literal operands to the division instructions are by far the most common, e.g. A = A >> 3, so are specialised
+	if operand <= int(COMBO_OPERAND_LITERAL_3) && operand >= 0 {
+		shift := operand
+		switch instruction {
+		case INSTRUCTION_ADV:
+			return func(state *compiledState) int {
+				state.registerA >>= shift
+				return nextAddress
+			}, nil
+		case INSTRUCTION_BDV:
+			return func(state *compiledState) int {
+				state.registerB = state.registerA >> shift
+				return nextAddress
+			}, nil
+		case INSTRUCTION_CDV:
+			return func(state *compiledState) int {
+				state.registerC = state.registerA >> shift
+				return nextAddress
+			}, nil
+		}
+	}
+
+	switch instruction {
+	case INSTRUCTION_BXL:
+		return func(state *compiledState) int {
+			state.registerB ^= operand
+			return nextAddress
+		}, nil
+	case INSTRUCTION_JNZ:
+		return func(state *compiledState) int {
+			if state.registerA != 0 {
+				return operand
+			}
+			return nextAddress
+		}, nil
+	case INSTRUCTION_BXC:
+		return func(state *compiledState) int {
+			state.registerB ^= state.registerC
+			return nextAddress
+		}, nil
+	}
+
+	comboOperand, err := compileComboOperand(ComboOperand(operand), address)
+	if err != nil {
+		return nil, err
+	}
+	switch instruction {
+	case INSTRUCTION_ADV:
+		return func(state *compiledState) int {
+			state.registerA = shiftRight(state.registerA, comboOperand(state))
+			return nextAddress
+		}, nil
+	case INSTRUCTION_BDV:
+		return func(state *compiledState) int {
+			state.registerB = shiftRight(state.registerA, comboOperand(state))
+			return nextAddress
+		}, nil
+	case INSTRUCTION_CDV:
+		return func(state *compiledState) int {
+			state.registerC = shiftRight(state.registerA, comboOperand(state))
+			return nextAddress
+		}, nil
+	case INSTRUCTION_BST:
+		return func(state *compiledState) int {
+			state.registerB = comboOperand(state) % 8
+			return nextAddress
+		}, nil
+	default: // INSTRUCTION_OUT
+		return func(state *compiledState) int {
+			state.output = append(state.output, comboOperand(state)%8)
+			return nextAddress
+		}, nil
+	}
+}
+
+// Find every address execution can reach, following the instruction pointer and jump targets from address 0.
+// Jump targets are literals, so this is known before execution.
+func reachableAddresses(program []int) []int {
+	reachable := make([]int, 0)
+	visited := make(map[int]bool)
+	addressStack := []int{0}
+	for len(addressStack) > 0 {
+		address := addressStack[len(addressStack)-1]
+		addressStack = addressStack[:len(addressStack)-1]
+		if address < 0 || address >= len(program)-1 || visited[address] {
+			continue
+		}
+		visited[address] = true
+		reachable = append(reachable, address)
+
+		addressStack = append(addressStack, address+2)
+		if Instruction(program[address]) == INSTRUCTION_JNZ {
+			addressStack = append(addressStack, program[address+1])
+		}
+	}
+	return reachable
+}
+
+// Compile a program into Go closures with the default step limit. See CompileWithMaxSteps.
+func Compile(program []int) (CompiledProgram, error) {
+	return CompileWithMaxSteps(program, DEFAULT_MAX_STEPS)
+}
+
+// Compile a program into Go closures, decoding every instruction once rather than on every step.
+//
+// Every reachable instruction is validated at compile time, so the errors TribitEmulator.ExecuteProgram would return for an invalid
+// instruction are returned here instead. The compiled program gives the same output as the emulator for any initial registers.
+// A non-positive maximum number of steps removes the step limit.
+func CompileWithMaxSteps(program []int, maxSteps int) (CompiledProgram, error) {
+	if len(program)%2 != 0 {
+		return nil, fmt.Errorf("%w: program length %d", ErrorOddProgramLength, len(program))
+	}
+
+	// Indexed by address, with jumps to odd addresses decoding instructions from the operands of others, as in the emulator
+	instructions := make([]compiledInstruction, len(program))
+	for _, address := range reachableAddresses(program) {
+		compiled, err := compileInstruction(program, address)
+		if err != nil {
+			return nil, err
+		}
+		instructions[address] = compiled
+	}
+
+	haltAddress := len(program) - 1
+	return func(registerA, registerB, registerC int) ([]int, error) {
+		state := compiledState{registerA, registerB, registerC, make([]int, 0)}
+		address := 0
+		for numSteps := 0; address >= 0 && address < haltAddress; numSteps += 1 {
+			if maxSteps > 0 && numSteps >= maxSteps {
+				return state.output, fmt.Errorf("%w: executed %d steps", ErrorStepLimitExceeded, numSteps)
+			}
+			address = instructions[address](&state)
+		}
+		return state.output, nil
+	}, nil
+}
@@ -0,0 +1,172 @@
+package tribitemulator
+
+import (
+	"encoding/json"
+	"io"
+)
+
+//go:generate stringer -type Register
+type Register int
+
+const (
+	REGISTER_A Register = 0
+	REGISTER_B Register = 1
+	REGISTER_C Register = 2
+)
+
+//go:generate stringer -type StopReason
+type StopReason int
+
+const (
+	// The instruction pointer reached the end of the program
+	STOP_REASON_HALTED StopReason = 0
+
+	// The instruction pointer reached a breakpoint. The instruction at the breakpoint has not been executed
+	STOP_REASON_BREAKPOINT StopReason = 1
+
+	// A watched register changed value during the last step
+	STOP_REASON_WATCHPOINT StopReason = 2
+
+	// The next instruction could not be executed, e.g. it is invalid or the step limit was reached
+	STOP_REASON_ERROR StopReason = 3
+)
+
+// A single executed instruction, along with the state of the emulator after execution.
+type TraceEntry struct {
+	InstructionPointer int         `json:"ip"`
+	Opcode             Instruction `json:"opcode"`
+	Operand            int         `json:"operand"`
+	RegisterA          int         `json:"A"`
+	RegisterB          int         `json:"B"`
+	RegisterC          int         `json:"C"`
+
+	// The value output by this instruction, or nil if nothing was output
+	Output *int `json:"output"`
+}
+
+// --------------------------------------------------------------------------------
+// State inspection
+
+func (emulator *TribitEmulator) InstructionPointer() int {
+	return emulator.instructionPointer
+}
+
+func (emulator *TribitEmulator) Program() []int {
+	return emulator.program
+}
+
+func (emulator *TribitEmulator) Output() []int {
+	return emulator.output
+}
+
+func (emulator *TribitEmulator) GetRegister(register Register) int {
+	switch register {
+	case REGISTER_A:
+		return emulator.registerA
+	case REGISTER_B:
+		return emulator.registerB
+	case REGISTER_C:
+		return emulator.registerC
+	}
+	return 0
+}
+
+// --------------------------------------------------------------------------------
+// Breakpoints and watchpoints
+
+// Stop execution (in Continue) before the instruction at the given instruction pointer is executed.
+func (emulator *TribitEmulator) AddBreakpoint(instructionPointer int) {
+	if emulator.breakpoints == nil {
+		emulator.breakpoints = make(map[int]bool)
+	}
+	emulator.breakpoints[instructionPointer] = true
+}
+
+func (emulator *TribitEmulator) RemoveBreakpoint(instructionPointer int) {
+	delete(emulator.breakpoints, instructionPointer)
+}
+
+// Stop execution (in Continue) after any instruction that changes the value of the given register.
+func (emulator *TribitEmulator) AddWatchpoint(register Register) {
+	if emulator.watchpoints == nil {
+		emulator.watchpoints = make(map[Register]bool)
+	}
+	emulator.watchpoints[register] = true
+}
+
+func (emulator *TribitEmulator) RemoveWatchpoint(register Register) {
+	delete(emulator.watchpoints, register)
+}
+
+// Execute instructions until the program halts, a breakpoint is reached, or a watched register changes.
+//
+// At least one instruction is executed, so calling Continue while stopped at a breakpoint moves past it.
+// Execution also stops if an instruction cannot be executed (see Step), in which case the error is returned.
+func (emulator *TribitEmulator) Continue() (StopReason, error) {
+	for {
+		watchedValues := make(map[Register]int, len(emulator.watchpoints))
+		for register := range emulator.watchpoints {
+			watchedValues[register] = emulator.GetRegister(register)
+		}
+
+		halted, err := emulator.Step()
+		if err != nil {
+			return STOP_REASON_ERROR, err
+		}
+		if halted {
+			return STOP_REASON_HALTED, nil
+		}
+		for register, priorValue := range watchedValues {
+			if emulator.GetRegister(register) != priorValue {
+				return STOP_REASON_WATCHPOINT, nil
+			}
+		}
+		if emulator.breakpoints[emulator.instructionPointer] {
+			return STOP_REASON_BREAKPOINT, nil
+		}
+	}
+}
+
+// --------------------------------------------------------------------------------
+// Execution trace
+
+// Start recording every executed instruction. Any existing trace is discarded.
+func (emulator *TribitEmulator) EnableTrace() {
+	emulator.traceEnabled = true
+	emulator.trace = make([]TraceEntry, 0)
+}
+
+func (emulator *TribitEmulator) DisableTrace() {
+	emulator.traceEnabled = false
+}
+
+func (emulator *TribitEmulator) Trace() []TraceEntry {
+	return emulator.trace
+}
+
+func (emulator *TribitEmulator) recordTrace(instructionPointer int, instruction Instruction, operand int, priorOutputLength int) {
+	entry := TraceEntry{
+		InstructionPointer: instructionPointer,
+		Opcode:             instruction,
+		Operand:            operand,
+		RegisterA:          emulator.registerA,
+		RegisterB:          emulator.registerB,
+		RegisterC:          emulator.registerC,
+	}
+	if len(emulator.output) > priorOutputLength {
+		outputValue := emulator.output[len(emulator.output)-1]
+		entry.Output = &outputValue
+	}
+	emulator.trace = append(emulator.trace, entry)
+}
+
+// Write the recorded trace as newline delimited JSON, one object per executed instruction.
+func (emulator *TribitEmulator) WriteTraceNDJSON(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	for _, entry := range emulator.trace {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,253 @@
+package tribitemulator
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrorUnrecognizedAssembly error = errors.New("line does not match any instruction")
+	ErrorInvalidComboOperand  error = errors.New("combo operand must be one of 0, 1, 2, 3, A, B, C, or RESERVED")
+	ErrorUndefinedLabel       error = errors.New("jump target label is not defined")
+	ErrorDuplicateLabel       error = errors.New("label is defined more than once")
+
+	comboOperandPattern = `(\d+|A|B|C|RESERVED)`
+	labelPattern        = `([A-Za-z_]\w*|\d+)`
+	divisionRegex       = regexp.MustCompile(`^([ABC]) = A >> ` + comboOperandPattern + `$`)
+	bxlRegex            = regexp.MustCompile(`^B = B \^ (\d+)$`)
+	bstRegex            = regexp.MustCompile(`^B = ` + comboOperandPattern + ` % 8$`)
+	jnzRegex            = regexp.MustCompile(`^if A != 0 goto ` + labelPattern + `$`)
+	bxcRegex            = regexp.MustCompile(`^B = B \^ C(?: \(operand (\d+)\))?$`)
+	outRegex            = regexp.MustCompile(`^out ` + comboOperandPattern + ` % 8$`)
+	wordRegex           = regexp.MustCompile(`^\.word (-?\d+)(?: (-?\d+))?$`)
+	labelRegex          = regexp.MustCompile(`^([A-Za-z_]\w*):$`)
+)
+
+// --------------------------------------------------------------------------------
+// Disassembly
+
+func disassembleComboOperand(operand int) string {
+	switch ComboOperand(operand) {
+	case COMBO_OPERAND_LITERAL_0, COMBO_OPERAND_LITERAL_1, COMBO_OPERAND_LITERAL_2, COMBO_OPERAND_LITERAL_3:
+		return strconv.Itoa(operand)
+	case COMBO_OPERAND_REGISTER_A:
+		return "A"
+	case COMBO_OPERAND_REGISTER_B:
+		return "B"
+	case COMBO_OPERAND_REGISTER_C:
+		return "C"
+	case COMBO_OPERAND_RESERVED:
+		return "RESERVED"
+	}
+	return strconv.Itoa(operand)
+}
+
+func jumpTargetLabel(address int) string {
+	return "L" + strconv.Itoa(address)
+}
+
+// Determine if an instruction/operand pair can be disassembled into pseudo-assembly,
+// or must be written as raw data.
+func isDisassemblable(instruction Instruction, operand int) bool {
+	if instruction < INSTRUCTION_ADV || instruction > INSTRUCTION_CDV {
+		return false
+	}
+	switch instruction {
+	case INSTRUCTION_ADV, INSTRUCTION_BDV, INSTRUCTION_CDV, INSTRUCTION_BST, INSTRUCTION_OUT:
+		return 0 <= operand && operand <= int(COMBO_OPERAND_RESERVED)
+	default:
+		return operand >= 0
+	}
+}
+
+// Find every address that is the target of a jump and can be labelled.
+func findJumpTargets(program []int) map[int]bool {
+	jumpTargets := make(map[int]bool)
+	for address := 0; address < len(program)-1; address += 2 {
+		if Instruction(program[address]) == INSTRUCTION_JNZ && program[address+1] >= 0 && program[address+1]%2 == 0 && program[address+1] < len(program) {
+			jumpTargets[program[address+1]] = true
+		}
+	}
+	return jumpTargets
+}
+
+func disassembleInstruction(program []int, address int, jumpTargets map[int]bool) string {
+	if address == len(program)-1 {
+		return fmt.Sprintf(".word %d", program[address])
+	}
+
+	instruction := Instruction(program[address])
+	operand := program[address+1]
+	if !isDisassemblable(instruction, operand) {
+		return fmt.Sprintf(".word %d %d", program[address], operand)
+	}
+
+	switch instruction {
+	case INSTRUCTION_ADV:
+		return "A = A >> " + disassembleComboOperand(operand)
+	case INSTRUCTION_BDV:
+		return "B = A >> " + disassembleComboOperand(operand)
+	case INSTRUCTION_CDV:
+		return "C = A >> " + disassembleComboOperand(operand)
+	case INSTRUCTION_BXL:
+		return "B = B ^ " + strconv.Itoa(operand)
+	case INSTRUCTION_BST:
+		return "B = " + disassembleComboOperand(operand) + " % 8"
+	case INSTRUCTION_JNZ:
+		if jumpTargets[operand] {
+			return "if A != 0 goto " + jumpTargetLabel(operand)
+		}
+		return "if A != 0 goto " + strconv.Itoa(operand)
+	case INSTRUCTION_BXC:
+		if operand != 0 {
+			return fmt.Sprintf("B = B ^ C (operand %d)", operand)
+		}
+		return "B = B ^ C"
+	case INSTRUCTION_OUT:
+		return "out " + disassembleComboOperand(operand) + " % 8"
+	}
+	return fmt.Sprintf(".word %d %d", program[address], operand)
+}
+
+// Disassemble the single instruction at the given address of a program.
+func DisassembleInstruction(program []int, address int) string {
+	if address < 0 || address >= len(program) {
+		return ""
+	}
+	return disassembleInstruction(program, address, findJumpTargets(program))
+}
+
+// Disassemble a program into readable pseudo-assembly, one instruction per line.
+//
+// Combo operands are resolved to the register names A, B, C or literals, and the targets of jumps
+// are given labels (L followed by the target address), placed on their own line before the target instruction.
+// Values that do not form a valid instruction (including a trailing value in an odd length program) are written as .word data.
+//
+// The output can be turned back into the same program using Assemble.
+func Disassemble(program []int) []string {
+	jumpTargets := findJumpTargets(program)
+	disassembly := make([]string, 0, len(program)/2)
+	for address := 0; address < len(program); address += 2 {
+		if jumpTargets[address] {
+			disassembly = append(disassembly, jumpTargetLabel(address)+":")
+		}
+		disassembly = append(disassembly, "\t"+disassembleInstruction(program, address, jumpTargets))
+	}
+
+	return disassembly
+}
+
+// --------------------------------------------------------------------------------
+// Assembly
+
+func assembleComboOperand(operandStr string) (int, error) {
+	switch operandStr {
+	case "A":
+		return int(COMBO_OPERAND_REGISTER_A), nil
+	case "B":
+		return int(COMBO_OPERAND_REGISTER_B), nil
+	case "C":
+		return int(COMBO_OPERAND_REGISTER_C), nil
+	case "RESERVED":
+		return int(COMBO_OPERAND_RESERVED), nil
+	}
+	operand, err := strconv.Atoi(operandStr)
+	if err != nil || operand < 0 || operand > int(COMBO_OPERAND_LITERAL_3) {
+		return 0, ErrorInvalidComboOperand
+	}
+	return operand, nil
+}
+
+// Assemble pseudo-assembly (in the format produced by Disassemble) into a program.
+//
+// Blank lines and anything following a ';' are ignored. Labels are any identifier followed by a colon,
+// and jumps may target either a label or a literal address. Errors report the (one-indexed) line they occurred on.
+func Assemble(assembly []string) ([]int, error) {
+	type unresolvedJump struct {
+		programIndex int
+		label        string
+		lineNumber   int
+	}
+
+	program := make([]int, 0, 2*len(assembly))
+	labelAddresses := make(map[string]int)
+	unresolvedJumps := make([]unresolvedJump, 0)
+
+	for lineIndex, line := range assembly {
+		lineNumber := lineIndex + 1
+		if commentIndex := strings.Index(line, ";"); commentIndex != -1 {
+			line = line[:commentIndex]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if matches := labelRegex.FindStringSubmatch(line); matches != nil {
+			if _, ok := labelAddresses[matches[1]]; ok {
+				return nil, fmt.Errorf("line %d: %w: %s", lineNumber, ErrorDuplicateLabel, matches[1])
+			}
+			labelAddresses[matches[1]] = len(program)
+			continue
+		}
+
+		if matches := divisionRegex.FindStringSubmatch(line); matches != nil {
+			operand, err := assembleComboOperand(matches[2])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+			instruction := map[string]Instruction{"A": INSTRUCTION_ADV, "B": INSTRUCTION_BDV, "C": INSTRUCTION_CDV}[matches[1]]
+			program = append(program, int(instruction), operand)
+		} else if matches := bxlRegex.FindStringSubmatch(line); matches != nil {
+			operand, _ := strconv.Atoi(matches[1])
+			program = append(program, int(INSTRUCTION_BXL), operand)
+		} else if matches := bstRegex.FindStringSubmatch(line); matches != nil {
+			operand, err := assembleComboOperand(matches[1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+			program = append(program, int(INSTRUCTION_BST), operand)
+		} else if matches := jnzRegex.FindStringSubmatch(line); matches != nil {
+			if address, err := strconv.Atoi(matches[1]); err == nil {
+				program = append(program, int(INSTRUCTION_JNZ), address)
+			} else {
+				unresolvedJumps = append(unresolvedJumps, unresolvedJump{len(program) + 1, matches[1], lineNumber})
+				program = append(program, int(INSTRUCTION_JNZ), 0)
+			}
+		} else if matches := bxcRegex.FindStringSubmatch(line); matches != nil {
+			operand := 0
+			if matches[1] != "" {
+				operand, _ = strconv.Atoi(matches[1])
+			}
+			program = append(program, int(INSTRUCTION_BXC), operand)
+		} else if matches := outRegex.FindStringSubmatch(line); matches != nil {
+			operand, err := assembleComboOperand(matches[1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+			program = append(program, int(INSTRUCTION_OUT), operand)
+		} else if matches := wordRegex.FindStringSubmatch(line); matches != nil {
+			value, _ := strconv.Atoi(matches[1])
+			program = append(program, value)
+			if matches[2] != "" {
+				value, _ = strconv.Atoi(matches[2])
+				program = append(program, value)
+			}
+		} else {
+			return nil, fmt.Errorf("line %d: %w: %q", lineNumber, ErrorUnrecognizedAssembly, line)
+		}
+	}
+
+	for _, jump := range unresolvedJumps {
+		address, ok := labelAddresses[jump.label]
+		if !ok {
+			return nil, fmt.Errorf("line %d: %w: %s", jump.lineNumber, ErrorUndefinedLabel, jump.label)
+		}
+		program[jump.programIndex] = address
+	}
+
+	return program, nil
+}
@@ -0,0 +1,110 @@
+package tribitemulator
+
+import (
+	"math/big"
+)
+
+// The arithmetic the tribit instructions need from a register, independent of the register width.
+//
+// Registers are treated as unsigned. Methods must not modify the receiver, as register values are shared freely.
+type RegisterValue[T any] interface {
+	// Create a register value from a small non-negative literal (a literal or combo operand)
+	FromLiteral(literal int) T
+
+	// Compute the receiver shifted right by the given value, i.e. receiver / 2^shift
+	ShiftRight(shift T) T
+
+	// Compute the bitwise XOR of the receiver and the other value
+	Xor(other T) T
+
+	// Get the lowest three bits of the receiver, i.e. receiver % 8
+	LowTribit() int
+
+	IsZero() bool
+}
+
+// --------------------------------------------------------------------------------
+// Uint64 registers
+
+// A 64 bit unsigned register. Shifts of 64 or more bits result in zero, rather than being taken modulo 64.
+type Uint64Register uint64
+
+func (register Uint64Register) FromLiteral(literal int) Uint64Register {
+	return Uint64Register(literal)
+}
+
+func (register Uint64Register) ShiftRight(shift Uint64Register) Uint64Register {
+	if shift >= 64 {
+		return 0
+	}
+	return register >> shift
+}
+
+func (register Uint64Register) Xor(other Uint64Register) Uint64Register {
+	return register ^ other
+}
+
+func (register Uint64Register) LowTribit() int {
+	return int(register & 7)
+}
+
+func (register Uint64Register) IsZero() bool {
+	return register == 0
+}
+
+// --------------------------------------------------------------------------------
+// Arbitrary precision registers
+
+// An arbitrary precision unsigned register. The zero value (with a nil value) is zero.
+type BigRegister struct {
+	value *big.Int
+}
+
+func NewBigRegister(value *big.Int) BigRegister {
+	return BigRegister{new(big.Int).Set(value)}
+}
+
+func (register BigRegister) bigInt() *big.Int {
+	if register.value == nil {
+		return new(big.Int)
+	}
+	return register.value
+}
+
+// Get a copy of the register value
+func (register BigRegister) Int() *big.Int {
+	return new(big.Int).Set(register.bigInt())
+}
+
+func (register BigRegister) String() string {
+	return register.bigInt().String()
+}
+
+func (register BigRegister) FromLiteral(literal int) BigRegister {
+	return BigRegister{big.NewInt(int64(literal))}
+}
+
+func (register BigRegister) ShiftRight(shift BigRegister) BigRegister {
+	shiftInt := shift.bigInt()
+	// Any shift longer than the value itself results in zero, and avoids converting a huge shift to uint
+	if shiftInt.Cmp(big.NewInt(int64(register.bigInt().BitLen()))) >= 0 {
+		return BigRegister{new(big.Int)}
+	}
+	return BigRegister{new(big.Int).Rsh(register.bigInt(), uint(shiftInt.Uint64()))}
+}
+
+func (register BigRegister) Xor(other BigRegister) BigRegister {
+	return BigRegister{new(big.Int).Xor(register.bigInt(), other.bigInt())}
+}
+
+func (register BigRegister) LowTribit() int {
+	bits := register.bigInt().Bits()
+	if len(bits) == 0 {
+		return 0
+	}
+	return int(bits[0] & 7)
+}
+
+func (register BigRegister) IsZero() bool {
+	return register.bigInt().Sign() == 0
+}
@@ -0,0 +1,162 @@
+package tribitemulator
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"slices"
+)
+
+const (
+	// The largest number of partial candidates kept while searching for initial values of register A
+	MAX_SEARCH_CANDIDATES int = 1 << 20
+)
+
+var (
+	ErrorNotShiftLoop       error = errors.New("program does not have the shape of a loop shifting A right by 3 bits per output")
+	ErrorTooManyCandidates  error = errors.New("too many candidate values of register A, the output does not constrain A enough")
+	ErrorNoInitialValueOfA  error = errors.New("no initial value of register A produces the target output")
+	registerComboOperandMap       = map[ComboOperand]Register{
+		COMBO_OPERAND_REGISTER_A: REGISTER_A,
+		COMBO_OPERAND_REGISTER_B: REGISTER_B,
+		COMBO_OPERAND_REGISTER_C: REGISTER_C,
+	}
+)
+
+// Check that a program has the shape the initial value search relies on:
+//
+//   - The program is a single loop, ending in the only jump, which jumps back to the start
+//   - The loop body shifts A right by exactly 3 bits (adv 3) exactly once, and does not otherwise write to A
+//   - The loop body outputs exactly once
+//   - B and C are always written before they are read in the loop body, so each output depends only on A
+//
+// Programs of this shape output one value per three bits of the initial value of A.
+func CheckShiftLoopShape(program []int) error {
+	if len(program)%2 != 0 || len(program) < 4 {
+		return fmt.Errorf("%w: program must be an even number of values containing at least two instructions", ErrorNotShiftLoop)
+	}
+	if Instruction(program[len(program)-2]) != INSTRUCTION_JNZ || program[len(program)-1] != 0 {
+		return fmt.Errorf("%w: program must end with a jump to the start", ErrorNotShiftLoop)
+	}
+
+	numShifts := 0
+	numOutputs := 0
+	writtenRegisters := map[Register]bool{REGISTER_A: true}
+	checkComboRead := func(address int, operand int) error {
+		if operand < 0 || operand >= int(COMBO_OPERAND_RESERVED) {
+			return fmt.Errorf("%w: invalid combo operand %d at address %d", ErrorNotShiftLoop, operand, address)
+		}
+		if register, ok := registerComboOperandMap[ComboOperand(operand)]; ok && !writtenRegisters[register] {
+			return fmt.Errorf("%w: register %v is read at address %d before it is written in the loop", ErrorNotShiftLoop, register, address)
+		}
+		return nil
+	}
+
+	for address := 0; address < len(program)-2; address += 2 {
+		instruction := Instruction(program[address])
+		operand := program[address+1]
+		switch instruction {
+		case INSTRUCTION_ADV:
+			if operand != 3 {
+				return fmt.Errorf("%w: register A is shifted by something other than 3 at address %d", ErrorNotShiftLoop, address)
+			}
+			numShifts += 1
+		case INSTRUCTION_BDV, INSTRUCTION_CDV:
+			if err := checkComboRead(address, operand); err != nil {
+				return err
+			}
+			if instruction == INSTRUCTION_BDV {
+				writtenRegisters[REGISTER_B] = true
+			} else {
+				writtenRegisters[REGISTER_C] = true
+			}
+		case INSTRUCTION_BST:
+			if err := checkComboRead(address, operand); err != nil {
+				return err
+			}
+			writtenRegisters[REGISTER_B] = true
+		case INSTRUCTION_BXL:
+			if !writtenRegisters[REGISTER_B] {
+				return fmt.Errorf("%w: register B is read at address %d before it is written in the loop", ErrorNotShiftLoop, address)
+			}
+		case INSTRUCTION_BXC:
+			if !writtenRegisters[REGISTER_B] || !writtenRegisters[REGISTER_C] {
+				return fmt.Errorf("%w: registers B and C are read at address %d before they are written in the loop", ErrorNotShiftLoop, address)
+			}
+		case INSTRUCTION_OUT:
+			if err := checkComboRead(address, operand); err != nil {
+				return err
+			}
+			numOutputs += 1
+		case INSTRUCTION_JNZ:
+			return fmt.Errorf("%w: program jumps at address %d before the end of the loop", ErrorNotShiftLoop, address)
+		default:
+			return fmt.Errorf("%w: invalid instruction %d at address %d", ErrorNotShiftLoop, instruction, address)
+		}
+	}
+
+	if numShifts != 1 {
+		return fmt.Errorf("%w: loop must shift A right by 3 exactly once, found %d shifts", ErrorNotShiftLoop, numShifts)
+	}
+	if numOutputs != 1 {
+		return fmt.Errorf("%w: loop must output exactly once, found %d outputs", ErrorNotShiftLoop, numOutputs)
+	}
+	return nil
+}
+
+// Find every initial value of register A for which the program outputs exactly the target output.
+//
+// The program must have the shape described by CheckShiftLoopShape, in which case each output is determined by
+// (at most) the remaining bits of A, three of which are consumed per loop. Candidates are built from the last output
+// backwards, extending each candidate by three bits and keeping those that reproduce the corresponding suffix of the target.
+//
+// Solutions are returned in ascending order. Registers B and C are written before being read, so their initial values do not matter.
+func FindInitialA(program []int, targetOutput []int) ([]*big.Int, error) {
+	if err := CheckShiftLoopShape(program); err != nil {
+		return nil, err
+	}
+	if len(targetOutput) == 0 {
+		// The loop always runs at least once, so always outputs at least once
+		return nil, ErrorNoInitialValueOfA
+	}
+
+	zero := big.NewInt(0)
+	candidates := []*big.Int{zero}
+	for suffixStart := len(targetOutput) - 1; suffixStart >= 0; suffixStart -= 1 {
+		targetSuffix := targetOutput[suffixStart:]
+		nextCandidates := make([]*big.Int, 0)
+		for _, candidate := range candidates {
+			for lowTribit := range 8 {
+				initialAValue := new(big.Int).Lsh(candidate, 3)
+				initialAValue.Add(initialAValue, big.NewInt(int64(lowTribit)))
+				// Every loop but the first only runs if A is non-zero, so only the first loop may start with A = 0
+				if suffixStart > 0 && initialAValue.Sign() == 0 {
+					continue
+				}
+
+				emulator := NewBigTribitEmulator(initialAValue, zero, zero)
+				output, err := emulator.ExecuteProgram(program)
+				if err != nil {
+					return nil, err
+				}
+				if slices.Equal(output, targetSuffix) {
+					nextCandidates = append(nextCandidates, initialAValue)
+				}
+			}
+		}
+		if len(nextCandidates) > MAX_SEARCH_CANDIDATES {
+			return nil, ErrorTooManyCandidates
+		}
+		slog.Debug("searched for initial values of A", "target suffix", targetSuffix, "number of candidates", len(nextCandidates))
+		candidates = nextCandidates
+	}
+
+	if len(candidates) == 0 {
+		return nil, ErrorNoInitialValueOfA
+	}
+	slices.SortFunc(candidates, func(a, b *big.Int) int {
+		return a.Cmp(b)
+	})
+	return candidates, nil
+}
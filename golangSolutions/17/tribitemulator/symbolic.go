@@ -0,0 +1,233 @@
+package tribitemulator
+
+import (
+	"fmt"
+	"log/slog"
+	"math/big"
+	"math/bits"
+)
+
+const (
+	// The default number of instructions symbolic execution may take before failing with ErrorStepLimitExceeded
+	DEFAULT_SYMBOLIC_MAX_STEPS int = 1 << 16
+)
+
+// A register during symbolic execution, as one expression per bit (least significant bit first)
+type symbolicRegister []BitExpression
+
+// A tribit emulator that executes programs over the unknown bits of the initial value of register A.
+//
+// Registers are held as boolean expressions over the variables a0, a1, ... (the bits of the initial A, least significant first),
+// and each output produces equations fixing its bits to those of a target output. The resulting BitEquationSystem
+// is solved for A without depending on the shape of the program, unlike FindInitialA.
+//
+// Jumps with a condition that depends on A are resolved by the target output: the jump is taken (and A is constrained to be
+// non-zero) while there is output left to produce, and otherwise falls through (constraining A to be zero).
+// This covers programs looping until A is exhausted, but not programs whose output count is unrelated to their jumps.
+type SymbolicTribitEmulator struct {
+	system             *BitEquationSystem
+	registerWidth      int
+	registerA          symbolicRegister
+	registerB          symbolicRegister
+	registerC          symbolicRegister
+	instructionPointer int
+	maxSteps           int
+}
+
+// Create a symbolic emulator with an unknown register A of numBitsA bits, and known initial values of registers B and C.
+func NewSymbolicTribitEmulator(numBitsA int, initRegisterB, initRegisterC int) *SymbolicTribitEmulator {
+	registerWidth := max(numBitsA, bits.Len(uint(initRegisterB)), bits.Len(uint(initRegisterC)), 3)
+	emulator := &SymbolicTribitEmulator{
+		system:        NewBitEquationSystem(numBitsA),
+		registerWidth: registerWidth,
+		maxSteps:      DEFAULT_SYMBOLIC_MAX_STEPS,
+	}
+
+	emulator.registerA = emulator.constantRegister(0)
+	for index := range numBitsA {
+		emulator.registerA[index] = emulator.system.Variable(index)
+	}
+	emulator.registerB = emulator.constantRegister(initRegisterB)
+	emulator.registerC = emulator.constantRegister(initRegisterC)
+	return emulator
+}
+
+// Set the maximum number of instructions executed before symbolic execution fails
+func (emulator *SymbolicTribitEmulator) SetMaxSteps(maxSteps int) {
+	emulator.maxSteps = maxSteps
+}
+
+func (emulator *SymbolicTribitEmulator) constantRegister(value int) symbolicRegister {
+	register := make(symbolicRegister, emulator.registerWidth)
+	for index := range register {
+		register[index] = emulator.system.Constant(index < bits.UintSize && uint(value)>>index&1 == 1)
+	}
+	return register
+}
+
+func (emulator *SymbolicTribitEmulator) getComboOperand(operand ComboOperand) (symbolicRegister, error) {
+	switch operand {
+	case COMBO_OPERAND_LITERAL_0, COMBO_OPERAND_LITERAL_1, COMBO_OPERAND_LITERAL_2, COMBO_OPERAND_LITERAL_3:
+		return emulator.constantRegister(int(operand)), nil
+	case COMBO_OPERAND_REGISTER_A:
+		return emulator.registerA, nil
+	case COMBO_OPERAND_REGISTER_B:
+		return emulator.registerB, nil
+	case COMBO_OPERAND_REGISTER_C:
+		return emulator.registerC, nil
+	}
+	return nil, fmt.Errorf("%w: combo operand %d at address %d", ErrorReservedOperand, operand, emulator.instructionPointer)
+}
+
+// Shift a register right by a symbolic amount, using a barrel shifter.
+//
+// Each bit of the shift that is below the register width selects between shifting by that power of two or not,
+// and if any larger bit of the shift is set the result is zero. Constant folding removes the stages for constant shift bits.
+func (emulator *SymbolicTribitEmulator) shiftRight(value symbolicRegister, shift symbolicRegister) symbolicRegister {
+	system := emulator.system
+	result := value
+	anyLargeShiftBit := BIT_EXPRESSION_FALSE
+	for shiftBitIndex, shiftBit := range shift {
+		if shiftBitIndex >= bits.Len(uint(emulator.registerWidth-1)) {
+			anyLargeShiftBit = system.Or(anyLargeShiftBit, shiftBit)
+			continue
+		}
+		if shiftBit == BIT_EXPRESSION_FALSE {
+			continue
+		}
+
+		shiftAmount := 1 << shiftBitIndex
+		shifted := make(symbolicRegister, emulator.registerWidth)
+		for index := range shifted {
+			shiftedBit := BIT_EXPRESSION_FALSE
+			if index+shiftAmount < emulator.registerWidth {
+				shiftedBit = result[index+shiftAmount]
+			}
+			shifted[index] = system.Mux(shiftBit, shiftedBit, result[index])
+		}
+		result = shifted
+	}
+
+	if anyLargeShiftBit != BIT_EXPRESSION_FALSE {
+		noLargeShift := system.Not(anyLargeShiftBit)
+		masked := make(symbolicRegister, emulator.registerWidth)
+		for index := range masked {
+			masked[index] = system.And(noLargeShift, result[index])
+		}
+		result = masked
+	}
+	return result
+}
+
+func (emulator *SymbolicTribitEmulator) xor(x, y symbolicRegister) symbolicRegister {
+	result := make(symbolicRegister, emulator.registerWidth)
+	for index := range result {
+		result[index] = emulator.system.Xor(x[index], y[index])
+	}
+	return result
+}
+
+// Take the low three bits of a register, i.e. register % 8
+func (emulator *SymbolicTribitEmulator) lowTribit(register symbolicRegister) symbolicRegister {
+	result := emulator.constantRegister(0)
+	copy(result[:3], register[:3])
+	return result
+}
+
+// Symbolically execute a program, returning the equations on the bits of the initial register A
+// under which the program outputs exactly the target output.
+func (emulator *SymbolicTribitEmulator) ExecuteProgram(program []int, targetOutput []int) (*BitEquationSystem, error) {
+	system := emulator.system
+	emulator.instructionPointer = 0
+	numOutputs := 0
+	if len(program)%2 != 0 {
+		return nil, fmt.Errorf("%w: program length %d", ErrorOddProgramLength, len(program))
+	}
+
+	for step := 0; emulator.instructionPointer < len(program)-1; step += 1 {
+		if step >= emulator.maxSteps {
+			return nil, fmt.Errorf("%w: executed %d steps symbolically", ErrorStepLimitExceeded, step)
+		}
+		instruction := Instruction(program[emulator.instructionPointer])
+		operand := program[emulator.instructionPointer+1]
+
+		switch instruction {
+		case INSTRUCTION_ADV, INSTRUCTION_BDV, INSTRUCTION_CDV:
+			comboOperand, err := emulator.getComboOperand(ComboOperand(operand))
+			if err != nil {
+				return nil, err
+			}
+			result := emulator.shiftRight(emulator.registerA, comboOperand)
+			switch instruction {
+			case INSTRUCTION_ADV:
+				emulator.registerA = result
+			case INSTRUCTION_BDV:
+				emulator.registerB = result
+			case INSTRUCTION_CDV:
+				emulator.registerC = result
+			}
+		case INSTRUCTION_BXL:
+			emulator.registerB = emulator.xor(emulator.registerB, emulator.constantRegister(operand))
+		case INSTRUCTION_BST:
+			comboOperand, err := emulator.getComboOperand(ComboOperand(operand))
+			if err != nil {
+				return nil, err
+			}
+			emulator.registerB = emulator.lowTribit(comboOperand)
+		case INSTRUCTION_JNZ:
+			registerANonZero := BIT_EXPRESSION_FALSE
+			for _, bit := range emulator.registerA {
+				registerANonZero = system.Or(registerANonZero, bit)
+			}
+			takeJump := registerANonZero == BIT_EXPRESSION_TRUE
+			if registerANonZero != BIT_EXPRESSION_TRUE && registerANonZero != BIT_EXPRESSION_FALSE {
+				takeJump = numOutputs < len(targetOutput)
+				system.AddEquation(registerANonZero, takeJump, fmt.Sprintf("jump at step %d (address %d) taken", step, emulator.instructionPointer))
+			}
+			if takeJump {
+				emulator.instructionPointer = operand
+				continue
+			}
+		case INSTRUCTION_BXC:
+			emulator.registerB = emulator.xor(emulator.registerB, emulator.registerC)
+		case INSTRUCTION_OUT:
+			comboOperand, err := emulator.getComboOperand(ComboOperand(operand))
+			if err != nil {
+				return nil, err
+			}
+			if numOutputs >= len(targetOutput) {
+				return nil, fmt.Errorf("%w: program outputs more than %d values", ErrorNoInitialValueOfA, len(targetOutput))
+			}
+			for bitIndex := range 3 {
+				targetBit := targetOutput[numOutputs]>>bitIndex&1 == 1
+				system.AddEquation(comboOperand[bitIndex], targetBit, fmt.Sprintf("output %d bit %d", numOutputs, bitIndex))
+			}
+			numOutputs += 1
+		default:
+			return nil, fmt.Errorf("%w: opcode %d at address %d", ErrorInvalidOpcode, instruction, emulator.instructionPointer)
+		}
+		emulator.instructionPointer += 2
+	}
+
+	if numOutputs != len(targetOutput) {
+		return nil, fmt.Errorf("%w: program halts after only %d of %d values", ErrorNoInitialValueOfA, numOutputs, len(targetOutput))
+	}
+	slog.Debug("symbolically executed program", "number of equations", len(system.Equations()), "number of expressions", system.NumExpressions())
+	return system, nil
+}
+
+// Find every initial value of register A (of at most numBitsA bits) for which the program outputs exactly the target output,
+// by symbolically executing the program and solving the resulting equations. Solutions are returned in ascending order.
+//
+// If numBitsA is not positive, three bits per target output value are used, enough for any program shifting A by 3 per output.
+func FindInitialASymbolic(program []int, targetOutput []int, initRegisterB, initRegisterC int, numBitsA int) ([]*big.Int, error) {
+	if numBitsA <= 0 {
+		numBitsA = 3 * len(targetOutput)
+	}
+	emulator := NewSymbolicTribitEmulator(numBitsA, initRegisterB, initRegisterC)
+	system, err := emulator.ExecuteProgram(program, targetOutput)
+	if err != nil {
+		return nil, err
+	}
+	return system.Solve(0)
+}
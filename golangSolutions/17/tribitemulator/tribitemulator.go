@@ -1,12 +1,30 @@
 package tribitemulator
 
 import (
-	"log/slog"
-	"math"
+	"errors"
+	"fmt"
+	"strconv"
 )
 
-func pow2(exponent int) int {
-	return int(math.Pow(2, float64(exponent)))
+const (
+	// The default number of instructions an emulator may execute before failing with ErrorStepLimitExceeded
+	DEFAULT_MAX_STEPS int = 1 << 24
+)
+
+var (
+	ErrorReservedOperand   error = errors.New("combo operand is reserved and does not appear in valid programs")
+	ErrorInvalidOpcode     error = errors.New("opcode does not correspond to any instruction")
+	ErrorOddProgramLength  error = errors.New("program has an odd length, so the final instruction has no operand")
+	ErrorStepLimitExceeded error = errors.New("program exceeded the maximum number of steps without halting")
+)
+
+// Compute value / 2^shift exactly, using a bit shift rather than floating point exponentiation.
+// Registers are treated as non-negative, and shifts past the width of an int result in zero.
+func shiftRight(value, shift int) int {
+	if shift < 0 || shift >= strconv.IntSize {
+		return 0
+	}
+	return value >> shift
 }
 
 type TribitEmulator struct {
@@ -14,6 +32,15 @@ type TribitEmulator struct {
 	registerB          int
 	registerC          int
 	instructionPointer int
+	program            []int
+	output             []int
+	numSteps           int
+	maxSteps           int
+
+	breakpoints  map[int]bool
+	watchpoints  map[Register]bool
+	traceEnabled bool
+	trace        []TraceEntry
 }
 
 func NewTribitEmulator(initRegisterA, initRegisterB, initRegisterC int) TribitEmulator {
@@ -21,74 +48,140 @@ func NewTribitEmulator(initRegisterA, initRegisterB, initRegisterC int) TribitEm
 		registerA: initRegisterA,
 		registerB: initRegisterB,
 		registerC: initRegisterC,
+		maxSteps:  DEFAULT_MAX_STEPS,
 	}
 }
 
-func (emulator TribitEmulator) getComboOperand(operand ComboOperand) int {
+// Set the number of instructions a loaded program may execute before failing with ErrorStepLimitExceeded.
+// A non-positive maximum removes the limit.
+func (emulator *TribitEmulator) SetMaxSteps(maxSteps int) {
+	emulator.maxSteps = maxSteps
+}
+
+func (emulator TribitEmulator) getComboOperand(operand ComboOperand) (int, error) {
 	switch operand {
 	case COMBO_OPERAND_LITERAL_0:
-		return 0
+		return 0, nil
 	case COMBO_OPERAND_LITERAL_1:
-		return 1
+		return 1, nil
 	case COMBO_OPERAND_LITERAL_2:
-		return 2
+		return 2, nil
 	case COMBO_OPERAND_LITERAL_3:
-		return 3
+		return 3, nil
 	case COMBO_OPERAND_REGISTER_A:
-		return emulator.registerA
+		return emulator.registerA, nil
 	case COMBO_OPERAND_REGISTER_B:
-		return emulator.registerB
+		return emulator.registerB, nil
 	case COMBO_OPERAND_REGISTER_C:
-		return emulator.registerC
+		return emulator.registerC, nil
 	}
 
-	slog.Error("unexpected combo operand encountered", "operand", operand)
-	return 0
+	return 0, fmt.Errorf("%w: combo operand %d at address %d", ErrorReservedOperand, operand, emulator.instructionPointer)
+}
+
+// Determine if an instruction interprets its operand as a combo operand, rather than a literal
+func usesComboOperand(instruction Instruction) bool {
+	switch instruction {
+	case INSTRUCTION_ADV, INSTRUCTION_BDV, INSTRUCTION_CDV, INSTRUCTION_BST, INSTRUCTION_OUT:
+		return true
+	}
+	return false
 }
 
-func (emulator TribitEmulator) ExecuteProgram(program []int) []int {
+// Load a program into the emulator, resetting the instruction pointer and output.
+// Registers are left as they are.
+func (emulator *TribitEmulator) LoadProgram(program []int) {
+	emulator.program = program
 	emulator.instructionPointer = 0
-	output := make([]int, 0)
+	emulator.output = make([]int, 0)
+	emulator.numSteps = 0
+	emulator.trace = nil
+}
 
-	// halt when instruction pointer reaches end of program
-	for emulator.instructionPointer < len(program)-1 {
-		instruction := Instruction(program[emulator.instructionPointer])
-		operand := program[emulator.instructionPointer+1]
-
-		// slog.Debug("program loop", "instruction pointer", emulator.instructionPointer, "instruction", instruction, "operand", operand, "emulator state", emulator)
-
-		switch instruction {
-		case INSTRUCTION_ADV:
-			comboOperand := emulator.getComboOperand(ComboOperand(operand))
-			emulator.registerA = emulator.registerA / pow2(comboOperand)
-		case INSTRUCTION_BDV:
-			comboOperand := emulator.getComboOperand(ComboOperand(operand))
-			emulator.registerB = emulator.registerA / pow2(comboOperand)
-		case INSTRUCTION_CDV:
-			comboOperand := emulator.getComboOperand(ComboOperand(operand))
-			emulator.registerC = emulator.registerA / pow2(comboOperand)
-		case INSTRUCTION_BXL:
-			emulator.registerB = emulator.registerB ^ operand
-		case INSTRUCTION_BST:
-			comboOperand := emulator.getComboOperand(ComboOperand(operand))
-			emulator.registerB = comboOperand % 8
-		case INSTRUCTION_JNZ:
-			if emulator.registerA != 0 {
-				emulator.instructionPointer = operand
-				continue // Don't increment instruction pointer at end of loop
-			}
-		case INSTRUCTION_BXC:
-			emulator.registerB = emulator.registerB ^ emulator.registerC
-		case INSTRUCTION_OUT:
-			comboOperand := emulator.getComboOperand(ComboOperand(operand))
-			output = append(output, comboOperand%8)
-		default:
-			slog.Error("encountered unexpected instruction", "instruction pointer", emulator.instructionPointer, "instruction", instruction)
+// Determine if the loaded program has halted, i.e. the instruction pointer has reached the end of the program
+func (emulator *TribitEmulator) IsHalted() bool {
+	return emulator.instructionPointer >= len(emulator.program)-1
+}
+
+// Execute the single instruction under the instruction pointer of the loaded program.
+//
+// Returns true if the program has halted after this step (or was already halted, in which case nothing is executed).
+// If the instruction is invalid, or the step limit has been reached, an error is returned and the emulator state is unchanged.
+func (emulator *TribitEmulator) Step() (halted bool, err error) {
+	if emulator.IsHalted() {
+		return true, nil
+	}
+	if len(emulator.program)%2 != 0 {
+		return false, fmt.Errorf("%w: program length %d", ErrorOddProgramLength, len(emulator.program))
+	}
+	if emulator.maxSteps > 0 && emulator.numSteps >= emulator.maxSteps {
+		return false, fmt.Errorf("%w: executed %d steps", ErrorStepLimitExceeded, emulator.numSteps)
+	}
+
+	stepInstructionPointer := emulator.instructionPointer
+	instruction := Instruction(emulator.program[emulator.instructionPointer])
+	operand := emulator.program[emulator.instructionPointer+1]
+	outputLength := len(emulator.output)
+	if instruction < INSTRUCTION_ADV || instruction > INSTRUCTION_CDV {
+		return false, fmt.Errorf("%w: opcode %d at address %d", ErrorInvalidOpcode, instruction, emulator.instructionPointer)
+	}
+	var comboOperand int
+	if usesComboOperand(instruction) {
+		if comboOperand, err = emulator.getComboOperand(ComboOperand(operand)); err != nil {
+			return false, err
 		}
+	}
+	emulator.numSteps += 1
+
+	switch instruction {
+	case INSTRUCTION_ADV:
+		emulator.registerA = shiftRight(emulator.registerA, comboOperand)
+	case INSTRUCTION_BDV:
+		emulator.registerB = shiftRight(emulator.registerA, comboOperand)
+	case INSTRUCTION_CDV:
+		emulator.registerC = shiftRight(emulator.registerA, comboOperand)
+	case INSTRUCTION_BXL:
+		emulator.registerB = emulator.registerB ^ operand
+	case INSTRUCTION_BST:
+		emulator.registerB = comboOperand % 8
+	case INSTRUCTION_JNZ:
+		if emulator.registerA != 0 {
+			emulator.instructionPointer = operand
+		} else {
+			emulator.instructionPointer += 2
+		}
+	case INSTRUCTION_BXC:
+		emulator.registerB = emulator.registerB ^ emulator.registerC
+	case INSTRUCTION_OUT:
+		emulator.output = append(emulator.output, comboOperand%8)
+	}
+	if instruction != INSTRUCTION_JNZ {
 		emulator.instructionPointer += 2
 	}
 
-	// slog.Debug("program halted", "instruction pointer", emulator.instructionPointer, "emulator state", emulator)
+	if emulator.traceEnabled {
+		emulator.recordTrace(stepInstructionPointer, instruction, operand, outputLength)
+	}
+	return emulator.IsHalted(), nil
+}
+
+// Execute a program from the start until it halts, returning the output.
+//
+// Returns the output so far and an error if the program is invalid or exceeds the maximum number of steps.
+func (emulator TribitEmulator) ExecuteProgram(program []int) ([]int, error) {
+	emulator.LoadProgram(program)
+	if len(program)%2 != 0 {
+		return nil, fmt.Errorf("%w: program length %d", ErrorOddProgramLength, len(program))
+	}
 
-	return output
+	// halt when instruction pointer reaches end of program
+	for {
+		halted, err := emulator.Step()
+		if err != nil {
+			return emulator.output, err
+		}
+		if halted {
+			return emulator.output, nil
+		}
+	}
 }
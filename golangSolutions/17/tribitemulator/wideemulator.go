@@ -0,0 +1,115 @@
+package tribitemulator
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// A tribit emulator that is agnostic to the width of its registers.
+//
+// Unlike TribitEmulator, which uses Go ints, this emulator is exact for any register type implementing RegisterValue,
+// e.g. Uint64Register for speed or BigRegister for registers of any size.
+type WideTribitEmulator[T RegisterValue[T]] struct {
+	registerA          T
+	registerB          T
+	registerC          T
+	instructionPointer int
+	maxSteps           int
+}
+
+func NewWideTribitEmulator[T RegisterValue[T]](initRegisterA, initRegisterB, initRegisterC T) WideTribitEmulator[T] {
+	return WideTribitEmulator[T]{
+		registerA: initRegisterA,
+		registerB: initRegisterB,
+		registerC: initRegisterC,
+		maxSteps:  DEFAULT_MAX_STEPS,
+	}
+}
+
+// Set the number of instructions a program may execute before failing with ErrorStepLimitExceeded.
+// A non-positive maximum removes the limit.
+func (emulator *WideTribitEmulator[T]) SetMaxSteps(maxSteps int) {
+	emulator.maxSteps = maxSteps
+}
+
+func NewUint64TribitEmulator(initRegisterA, initRegisterB, initRegisterC uint64) WideTribitEmulator[Uint64Register] {
+	return NewWideTribitEmulator(Uint64Register(initRegisterA), Uint64Register(initRegisterB), Uint64Register(initRegisterC))
+}
+
+func NewBigTribitEmulator(initRegisterA, initRegisterB, initRegisterC *big.Int) WideTribitEmulator[BigRegister] {
+	return NewWideTribitEmulator(NewBigRegister(initRegisterA), NewBigRegister(initRegisterB), NewBigRegister(initRegisterC))
+}
+
+func (emulator WideTribitEmulator[T]) getComboOperand(operand ComboOperand) (T, error) {
+	switch operand {
+	case COMBO_OPERAND_LITERAL_0, COMBO_OPERAND_LITERAL_1, COMBO_OPERAND_LITERAL_2, COMBO_OPERAND_LITERAL_3:
+		return emulator.registerA.FromLiteral(int(operand)), nil
+	case COMBO_OPERAND_REGISTER_A:
+		return emulator.registerA, nil
+	case COMBO_OPERAND_REGISTER_B:
+		return emulator.registerB, nil
+	case COMBO_OPERAND_REGISTER_C:
+		return emulator.registerC, nil
+	}
+
+	return emulator.registerA.FromLiteral(0), fmt.Errorf("%w: combo operand %d at address %d", ErrorReservedOperand, operand, emulator.instructionPointer)
+}
+
+func (emulator WideTribitEmulator[T]) GetRegisters() (T, T, T) {
+	return emulator.registerA, emulator.registerB, emulator.registerC
+}
+
+// Execute a program from the start until it halts, returning the output.
+//
+// Returns the output so far and an error if the program is invalid or exceeds the maximum number of steps.
+func (emulator WideTribitEmulator[T]) ExecuteProgram(program []int) ([]int, error) {
+	emulator.instructionPointer = 0
+	output := make([]int, 0)
+	if len(program)%2 != 0 {
+		return nil, fmt.Errorf("%w: program length %d", ErrorOddProgramLength, len(program))
+	}
+
+	// halt when instruction pointer reaches end of program
+	for numSteps := 0; emulator.instructionPointer < len(program)-1; numSteps += 1 {
+		if emulator.maxSteps > 0 && numSteps >= emulator.maxSteps {
+			return output, fmt.Errorf("%w: executed %d steps", ErrorStepLimitExceeded, numSteps)
+		}
+		instruction := Instruction(program[emulator.instructionPointer])
+		operand := program[emulator.instructionPointer+1]
+		if instruction < INSTRUCTION_ADV || instruction > INSTRUCTION_CDV {
+			return output, fmt.Errorf("%w: opcode %d at address %d", ErrorInvalidOpcode, instruction, emulator.instructionPointer)
+		}
+		var comboOperand T
+		if usesComboOperand(instruction) {
+			var err error
+			if comboOperand, err = emulator.getComboOperand(ComboOperand(operand)); err != nil {
+				return output, err
+			}
+		}
+
+		switch instruction {
+		case INSTRUCTION_ADV:
+			emulator.registerA = emulator.registerA.ShiftRight(comboOperand)
+		case INSTRUCTION_BDV:
+			emulator.registerB = emulator.registerA.ShiftRight(comboOperand)
+		case INSTRUCTION_CDV:
+			emulator.registerC = emulator.registerA.ShiftRight(comboOperand)
+		case INSTRUCTION_BXL:
+			emulator.registerB = emulator.registerB.Xor(emulator.registerB.FromLiteral(operand))
+		case INSTRUCTION_BST:
+			emulator.registerB = emulator.registerB.FromLiteral(comboOperand.LowTribit())
+		case INSTRUCTION_JNZ:
+			if !emulator.registerA.IsZero() {
+				emulator.instructionPointer = operand
+				continue // Don't increment instruction pointer at end of loop
+			}
+		case INSTRUCTION_BXC:
+			emulator.registerB = emulator.registerB.Xor(emulator.registerC)
+		case INSTRUCTION_OUT:
+			output = append(output, comboOperand.LowTribit())
+		}
+		emulator.instructionPointer += 2
+	}
+
+	return output, nil
+}
@@ -0,0 +1,210 @@
+package gridrender
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"io"
+)
+
+const (
+	PNG_SIGNATURE string = "\x89PNG\r\n\x1a\n"
+
+	// APNG frame delays are stored as a fraction of a second, with this denominator
+	APNG_DELAY_DENOMINATOR int = 100
+)
+
+var (
+	ErrorMalformedPNG error = errors.New("encoded frame is not a well formed PNG")
+)
+
+// Writes an animated PNG one frame at a time.
+//
+// Each frame is encoded by image/png and its image data moved into APNG frame chunks. The number of frames must be
+// written before any image data, so the writer must be seekable to fill in the count once the animation is closed.
+type APNGWriter struct {
+	writer              io.WriteSeeker
+	width               int
+	height              int
+	numFrames           int
+	sequenceNumber      int
+	animationControlPos int64
+	closed              bool
+}
+
+// Start an APNG of the given size. Nothing is written until the first frame.
+func NewAPNGWriter(writer io.WriteSeeker, width, height int) *APNGWriter {
+	return &APNGWriter{
+		writer: writer,
+		width:  width,
+		height: height,
+	}
+}
+
+type pngChunk struct {
+	chunkType string
+	data      []byte
+}
+
+func (apngWriter *APNGWriter) writeChunk(chunk pngChunk) error {
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint32(header, uint32(len(chunk.data)))
+	copy(header[4:], chunk.chunkType)
+
+	checksum := crc32.NewIEEE()
+	checksum.Write(header[4:])
+	checksum.Write(chunk.data)
+	footer := binary.BigEndian.AppendUint32(nil, checksum.Sum32())
+
+	for _, part := range [][]byte{header, chunk.data, footer} {
+		if _, err := apngWriter.writer.Write(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Split an encoded PNG into its chunks
+func readPNGChunks(encoded []byte) ([]pngChunk, error) {
+	if !bytes.HasPrefix(encoded, []byte(PNG_SIGNATURE)) {
+		return nil, ErrorMalformedPNG
+	}
+	encoded = encoded[len(PNG_SIGNATURE):]
+
+	chunks := make([]pngChunk, 0)
+	for len(encoded) > 0 {
+		if len(encoded) < 12 {
+			return nil, ErrorMalformedPNG
+		}
+		dataLength := int(binary.BigEndian.Uint32(encoded))
+		if len(encoded) < 12+dataLength {
+			return nil, ErrorMalformedPNG
+		}
+		chunks = append(chunks, pngChunk{string(encoded[4:8]), encoded[8 : 8+dataLength]})
+		encoded = encoded[12+dataLength:]
+	}
+	return chunks, nil
+}
+
+func (apngWriter *APNGWriter) animationControlChunk() pngChunk {
+	data := binary.BigEndian.AppendUint32(nil, uint32(apngWriter.numFrames))
+	data = binary.BigEndian.AppendUint32(data, 0) // loop forever
+	return pngChunk{"acTL", data}
+}
+
+func (apngWriter *APNGWriter) nextSequenceNumber() uint32 {
+	apngWriter.sequenceNumber += 1
+	return uint32(apngWriter.sequenceNumber - 1)
+}
+
+// Write a frame, displayed for the given delay in hundredths of a second.
+// Every frame must be the size of the animation, and should share a palette so the PNG header is the same for each.
+func (apngWriter *APNGWriter) WriteFrame(frame *image.Paletted, delay int) error {
+	if apngWriter.closed {
+		return ErrorWriterClosed
+	}
+	if frame.Bounds().Dx() != apngWriter.width || frame.Bounds().Dy() != apngWriter.height {
+		return ErrorFrameSize
+	}
+
+	var encodedFrame bytes.Buffer
+	if err := png.Encode(&encodedFrame, frame); err != nil {
+		return err
+	}
+	chunks, err := readPNGChunks(encodedFrame.Bytes())
+	if err != nil {
+		return err
+	}
+
+	isFirstFrame := apngWriter.numFrames == 0
+	apngWriter.numFrames += 1
+	if isFirstFrame {
+		if _, err := io.WriteString(apngWriter.writer, PNG_SIGNATURE); err != nil {
+			return err
+		}
+	}
+
+	frameControlData := binary.BigEndian.AppendUint32(nil, apngWriter.nextSequenceNumber())
+	frameControlData = binary.BigEndian.AppendUint32(frameControlData, uint32(apngWriter.width))
+	frameControlData = binary.BigEndian.AppendUint32(frameControlData, uint32(apngWriter.height))
+	frameControlData = binary.BigEndian.AppendUint32(frameControlData, 0) // x offset
+	frameControlData = binary.BigEndian.AppendUint32(frameControlData, 0) // y offset
+	frameControlData = binary.BigEndian.AppendUint16(frameControlData, uint16(delay))
+	frameControlData = binary.BigEndian.AppendUint16(frameControlData, uint16(APNG_DELAY_DENOMINATOR))
+	frameControlData = append(frameControlData, 0, 0) // no disposal, overwrite previous frame
+	frameControlWritten := false
+
+	for _, chunk := range chunks {
+		switch chunk.chunkType {
+		case "IHDR":
+			if !isFirstFrame {
+				continue
+			}
+			if err := apngWriter.writeChunk(chunk); err != nil {
+				return err
+			}
+			// Remember where the frame count is, to fill it in when closing
+			if apngWriter.animationControlPos, err = apngWriter.writer.Seek(0, io.SeekCurrent); err != nil {
+				return err
+			}
+			if err := apngWriter.writeChunk(apngWriter.animationControlChunk()); err != nil {
+				return err
+			}
+		case "IDAT":
+			if !frameControlWritten {
+				if err := apngWriter.writeChunk(pngChunk{"fcTL", frameControlData}); err != nil {
+					return err
+				}
+				frameControlWritten = true
+			}
+			if isFirstFrame {
+				err = apngWriter.writeChunk(chunk)
+			} else {
+				frameData := binary.BigEndian.AppendUint32(nil, apngWriter.nextSequenceNumber())
+				err = apngWriter.writeChunk(pngChunk{"fdAT", append(frameData, chunk.data...)})
+			}
+			if err != nil {
+				return err
+			}
+		case "IEND":
+		default:
+			// Ancillary chunks and the palette describe the whole image, so are only taken from the first frame
+			if isFirstFrame {
+				if err := apngWriter.writeChunk(chunk); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Write the end of the PNG and fill in the number of frames. The underlying writer is not closed.
+func (apngWriter *APNGWriter) Close() error {
+	if apngWriter.closed {
+		return ErrorWriterClosed
+	}
+	apngWriter.closed = true
+	if apngWriter.numFrames == 0 {
+		return nil
+	}
+	if err := apngWriter.writeChunk(pngChunk{"IEND", nil}); err != nil {
+		return err
+	}
+
+	endPos, err := apngWriter.writer.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := apngWriter.writer.Seek(apngWriter.animationControlPos, io.SeekStart); err != nil {
+		return err
+	}
+	if err := apngWriter.writeChunk(apngWriter.animationControlChunk()); err != nil {
+		return err
+	}
+	_, err = apngWriter.writer.Seek(endPos, io.SeekStart)
+	return err
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"hmcalister/AdventOfCode/gridrender"
 	"hmcalister/AdventOfCode/gridutils"
 	"hmcalister/AdventOfCode/maze"
 	"log/slog"
@@ -19,11 +20,22 @@ const (
 	CPU_PROFILE_FILEPATH string = "profile"
 )
 
+var (
+	searchStrategy    int
+	compareStrategies bool
+	renderFormat      string
+	renderFilePath    string
+)
+
 func main() {
 	debugFlag := flag.Bool("debug", false, "Debug Flag")
 	inputFilePath := flag.String("inputFile", "puzzleInput", "Path to input file.")
 	selectedPart := flag.Int("part", 0, "Part to execute. Must be 1 or 2.")
 	profile := flag.Bool("profile", false, "Flag to profile program")
+	flag.IntVar(&searchStrategy, "searchStrategy", int(maze.SEARCH_STRATEGY_ASTAR), "Search strategy to use. 0 for A*, 1 for bidirectional A*, 2 for jump point search.")
+	flag.BoolVar(&compareStrategies, "compareStrategies", false, "Run every search strategy in part 1 and report the nodes each expanded.")
+	flag.StringVar(&renderFormat, "renderFormat", gridrender.FORMAT_ASCII, "Format to render the optimal path in part 1. One of ascii, ansi, or png.")
+	flag.StringVar(&renderFilePath, "renderFile", "", "File to render the optimal path in part 1 to. Defaults to stdout.")
 	flag.Parse()
 	if *profile {
 		f, err := os.Create(CPU_PROFILE_FILEPATH)
@@ -107,16 +119,52 @@ func parseInput(fileScanner *bufio.Scanner) (int, int, []gridutils.Coordinate) {
 	return mazeWidth, mazeHeight, fallingByteCoords
 }
 
+// Render the canvas in the selected format, to the selected file or stdout
+func renderMaze(canvas *gridrender.Canvas) error {
+	if renderFilePath == "" {
+		err := gridrender.Render(os.Stdout, canvas, renderFormat, gridrender.DEFAULT_CELL_SIZE)
+		fmt.Println()
+		return err
+	}
+
+	renderFile, err := os.Create(renderFilePath)
+	if err != nil {
+		return err
+	}
+	defer renderFile.Close()
+	return gridrender.Render(renderFile, canvas, renderFormat, gridrender.DEFAULT_CELL_SIZE)
+}
+
 func Part01(fileScanner *bufio.Scanner) (int, error) {
 	mazeWidth, mazeHeight, fallingByteCoords := parseInput(fileScanner)
 	slog.Debug("parsed input", "maze width", mazeWidth, "maze height", mazeHeight, "num falling bytes", len(fallingByteCoords))
-	maze := maze.NewMaze(mazeWidth, mazeHeight, fallingByteCoords[:1024])
-	fmt.Println(maze)
-	optimalPath, err := maze.ComputeOptimalPath()
+	mazeData := maze.NewMaze(mazeWidth, mazeHeight, fallingByteCoords[:min(1024, len(fallingByteCoords))])
+	fmt.Println(mazeData)
+
+	if compareStrategies {
+		for _, strategy := range maze.AllSearchStrategies {
+			mazeData.SetSearchStrategy(strategy)
+			strategyStartTime := time.Now()
+			optimalPath, statistics, err := mazeData.ComputeOptimalPathWithStatistics()
+			slog.Info("compared search strategy",
+				"strategy", strategy.String(),
+				"nodes expanded", statistics.NodesExpanded,
+				"path length", len(optimalPath),
+				"error", err,
+				"time elapsed (ns)", time.Since(strategyStartTime).Nanoseconds(),
+			)
+		}
+	}
+
+	mazeData.SetSearchStrategy(maze.SearchStrategy(searchStrategy))
+	optimalPath, statistics, err := mazeData.ComputeOptimalPathWithStatistics()
 	if err != nil {
 		return -1, err
 	}
-	fmt.Println(maze.StringWithPath(optimalPath))
+	slog.Info("computed optimal path", "strategy", statistics.Strategy.String(), "nodes expanded", statistics.NodesExpanded)
+	if err := renderMaze(mazeData.Canvas(gridrender.PathOverlay{Path: optimalPath, Style: maze.PathStyle})); err != nil {
+		return -1, err
+	}
 
 	return len(optimalPath) - 1, nil
 }
@@ -131,8 +179,9 @@ func Part02(fileScanner *bufio.Scanner) (int, error) {
 	for lowerSearchBound < upperSearchBound-1 {
 		byteIndex = (lowerSearchBound + upperSearchBound) / 2
 		slog.Info("attempting to block maze", "byte index", byteIndex, "lower search bound", lowerSearchBound, "upper search bound", upperSearchBound)
-		maze := maze.NewMaze(mazeWidth, mazeHeight, fallingByteCoords[:byteIndex+1])
-		_, err := maze.ComputeOptimalPath()
+		mazeData := maze.NewMaze(mazeWidth, mazeHeight, fallingByteCoords[:byteIndex+1])
+		mazeData.SetSearchStrategy(maze.SearchStrategy(searchStrategy))
+		_, err := mazeData.ComputeOptimalPath()
 
 		if err == nil {
 			lowerSearchBound = byteIndex
@@ -1,7 +1,7 @@
 package maze
 
 import (
-	"errors"
+	"hmcalister/AdventOfCode/gridrender"
 	"hmcalister/AdventOfCode/gridutils"
 	"log/slog"
 	"math"
@@ -12,24 +12,29 @@ import (
 )
 
 type Maze struct {
-	startPosition gridutils.Coordinate
-	endPosition   gridutils.Coordinate
-	coordinateMap *hashset.HashSet[gridutils.Coordinate]
-	mazeWidth     int
-	mazeHeight    int
-	gScore        map[gridutils.Coordinate]int
-	fScore        map[gridutils.Coordinate]int
+	startPosition    gridutils.Coordinate
+	endPosition      gridutils.Coordinate
+	coordinateMap    *hashset.HashSet[gridutils.Coordinate]
+	terrainMap       map[gridutils.Coordinate]Terrain
+	minimumEntryCost int
+	mazeWidth        int
+	mazeHeight       int
+	searchStrategy   SearchStrategy
+	gScore           map[gridutils.Coordinate]int
+	fScore           map[gridutils.Coordinate]int
 }
 
 func NewMaze(mazeWidth, mazeHeight int, byteCoords []gridutils.Coordinate) Maze {
 	maze := Maze{
-		startPosition: gridutils.Coordinate{X: 0, Y: 0},
-		endPosition:   gridutils.Coordinate{X: mazeWidth - 1, Y: mazeHeight - 1},
-		coordinateMap: hashset.New[gridutils.Coordinate](),
-		mazeWidth:     mazeWidth,
-		mazeHeight:    mazeHeight,
-		gScore:        make(map[gridutils.Coordinate]int),
-		fScore:        make(map[gridutils.Coordinate]int),
+		startPosition:    gridutils.Coordinate{X: 0, Y: 0},
+		endPosition:      gridutils.Coordinate{X: mazeWidth - 1, Y: mazeHeight - 1},
+		coordinateMap:    hashset.New[gridutils.Coordinate](),
+		terrainMap:       make(map[gridutils.Coordinate]Terrain),
+		minimumEntryCost: emptyTerrain.EntryCost,
+		mazeWidth:        mazeWidth,
+		mazeHeight:       mazeHeight,
+		gScore:           make(map[gridutils.Coordinate]int),
+		fScore:           make(map[gridutils.Coordinate]int),
 	}
 
 	for y := 0; y < mazeHeight; y += 1 {
@@ -46,58 +51,82 @@ func NewMaze(mazeWidth, mazeHeight int, byteCoords []gridutils.Coordinate) Maze
 	return maze
 }
 
-// --------------------------------------------------------------------------------
-// Print methods
+// Create a new maze from a grid of terrain runes, then remove the coordinates of any falling bytes.
+//
+// The maze starts in the top left and ends in the bottom right, unless the start or end runes are present in the grid.
+// Runes that are not walls and do not match any terrain type are treated as walls.
+func NewMazeWithTerrain(mazeStrings []string, terrainTypes []Terrain, byteCoords []gridutils.Coordinate) Maze {
+	maze := Maze{
+		startPosition:    gridutils.Coordinate{X: 0, Y: 0},
+		endPosition:      gridutils.Coordinate{X: len(mazeStrings[0]) - 1, Y: len(mazeStrings) - 1},
+		coordinateMap:    hashset.New[gridutils.Coordinate](),
+		terrainMap:       make(map[gridutils.Coordinate]Terrain),
+		minimumEntryCost: math.MaxInt,
+		mazeWidth:        len(mazeStrings[0]),
+		mazeHeight:       len(mazeStrings),
+		gScore:           make(map[gridutils.Coordinate]int),
+		fScore:           make(map[gridutils.Coordinate]int),
+	}
 
-func (maze Maze) String() string {
-	mazeString := make([]rune, maze.mazeHeight*(maze.mazeWidth+1))
-	mazeStringIndex := 0
-	for y := 0; y < maze.mazeHeight; y += 1 {
-		for x := 0; x < maze.mazeWidth; x += 1 {
-			c := gridutils.Coordinate{X: x, Y: y}
-			if c == maze.endPosition {
-				mazeString[mazeStringIndex] = END_RUNE
-			} else if c == maze.startPosition {
-				mazeString[mazeStringIndex] = START_RUNE
-			} else if !maze.coordinateMap.Contains(c) {
-				mazeString[mazeStringIndex] = WALL_RUNE
-			} else {
-				mazeString[mazeStringIndex] = '.'
-			}
-			mazeStringIndex += 1
+	terrainByRune := make(map[rune]Terrain)
+	for _, terrain := range terrainTypes {
+		if terrain.EntryCost < 0 {
+			slog.Error("terrain has negative entry cost, treating as zero", "terrain", terrain)
+			terrain.EntryCost = 0
 		}
-		mazeString[mazeStringIndex] = '\n'
-		mazeStringIndex += 1
+		terrainByRune[terrain.Rune] = terrain
 	}
-	return string(mazeString)
-}
 
-func (maze Maze) StringWithPath(path []gridutils.Coordinate) string {
-	mazeString := make([]rune, maze.mazeHeight*(maze.mazeWidth+1))
-	mazeStringIndex := 0
-	for y := 0; y < maze.mazeHeight; y += 1 {
-		for x := 0; x < maze.mazeWidth; x += 1 {
+	for y, row := range mazeStrings {
+		for x, cell := range row {
 			c := gridutils.Coordinate{X: x, Y: y}
-			if c == maze.endPosition {
-				mazeString[mazeStringIndex] = END_RUNE
-			} else if c == maze.startPosition {
-				mazeString[mazeStringIndex] = START_RUNE
-			} else if !maze.coordinateMap.Contains(c) {
-				mazeString[mazeStringIndex] = WALL_RUNE
-			} else {
-				mazeString[mazeStringIndex] = '.'
+			switch cell {
+			case START_RUNE:
+				maze.startPosition = c
+				slog.Debug("found start position", "coordinate", c)
+			case END_RUNE:
+				maze.endPosition = c
+				slog.Debug("found end position", "coordinate", c)
+			case WALL_RUNE:
+				continue
 			}
-			mazeStringIndex += 1
+
+			terrain, ok := terrainByRune[cell]
+			if !ok {
+				if cell != START_RUNE && cell != END_RUNE {
+					slog.Debug("found unexpected rune", "rune", cell, "coordinate", c)
+					continue
+				}
+				terrain = emptyTerrain
+			}
+			maze.coordinateMap.Add(c)
+			if terrain != emptyTerrain {
+				maze.terrainMap[c] = terrain
+			}
+			maze.minimumEntryCost = min(maze.minimumEntryCost, terrain.EntryCost)
 		}
-		mazeString[mazeStringIndex] = '\n'
-		mazeStringIndex += 1
 	}
-	for _, pathStep := range path {
-		linearCoordinate := (maze.mazeWidth+1)*pathStep.Y + pathStep.X
-		mazeString[linearCoordinate] = 'O'
+	if maze.minimumEntryCost == math.MaxInt {
+		maze.minimumEntryCost = 0
 	}
 
-	return string(mazeString)
+	for _, fallingByteCoordinate := range byteCoords {
+		maze.coordinateMap.Remove(fallingByteCoordinate)
+		slog.Debug("removing coordinate", "coordinate", fallingByteCoordinate)
+	}
+
+	return maze
+}
+
+// --------------------------------------------------------------------------------
+// Print methods
+
+func (maze Maze) String() string {
+	return gridrender.RenderASCII(maze.Canvas())
+}
+
+func (maze Maze) StringWithPath(path []gridutils.Coordinate) string {
+	return gridrender.RenderASCII(maze.Canvas(gridrender.PathOverlay{Path: path, Style: PathStyle}))
 }
 
 // --------------------------------------------------------------------------------
@@ -114,7 +143,7 @@ func (maze Maze) heuristic(step gridutils.Coordinate) int {
 		deltaY *= -1
 	}
 
-	return deltaX + deltaY
+	return maze.minimumEntryCost * (deltaX + deltaY)
 }
 
 func (maze Maze) getGScore(step gridutils.Coordinate) int {
@@ -139,9 +168,9 @@ func (maze Maze) expandStepSingleOptimalPath(
 	stepGScore := maze.getGScore(step)
 
 	for _, direction := range []gridutils.Direction{gridutils.DIRECTION_UP, gridutils.DIRECTION_RIGHT, gridutils.DIRECTION_DOWN, gridutils.DIRECTION_LEFT} {
-		nextStep := step.Step(direction)
-		if maze.coordinateMap.Contains(nextStep) {
-			forwardGScoreViaCurrent := stepGScore + 1
+		nextStep, stepCost, ok := maze.tryStep(step, direction)
+		if ok {
+			forwardGScoreViaCurrent := stepGScore + stepCost
 			forwardGScorePrior := maze.getGScore(nextStep)
 			if forwardGScoreViaCurrent < forwardGScorePrior {
 				slog.Debug("expanded better path to next neighbor", "current step", step, "next step", nextStep, "next g", forwardGScoreViaCurrent)
@@ -158,8 +187,18 @@ func (maze Maze) expandStepSingleOptimalPath(
 	}
 }
 
-// Find the optimal path using A* pathfinding
+// Find the optimal path using the search strategy of the maze (A* pathfinding, unless set otherwise)
 func (maze Maze) ComputeOptimalPath() ([]gridutils.Coordinate, error) {
+	optimalPath, _, err := maze.ComputeOptimalPathWithStatistics()
+	return optimalPath, err
+}
+
+// Find the optimal path using A* pathfinding
+func (maze Maze) computeOptimalPathAStar() ([]gridutils.Coordinate, SearchStatistics, error) {
+	statistics := SearchStatistics{Strategy: SEARCH_STRATEGY_ASTAR}
+	clear(maze.gScore)
+	clear(maze.fScore)
+
 	pathfindStepComparator := func(a, b gridutils.Coordinate) int {
 		return maze.getFScore(a) - maze.getFScore(b)
 	}
@@ -173,22 +212,17 @@ func (maze Maze) ComputeOptimalPath() ([]gridutils.Coordinate, error) {
 
 	for openset.Size() > 0 {
 		currentStep, _ := openset.Remove()
+		statistics.NodesExpanded += 1
 		slog.Debug("expanding node", "current step", currentStep)
 
 		if currentStep.Equal(maze.endPosition) {
-			reconstructedPath := make([]gridutils.Coordinate, 0)
-			reconstructedStep := currentStep
-			for reconstructedStep != maze.startPosition {
-				reconstructedPath = append(reconstructedPath, reconstructedStep)
-				reconstructedStep = cameFrom[reconstructedStep]
-			}
-			reconstructedPath = append(reconstructedPath, maze.startPosition)
+			reconstructedPath := walkCameFrom(currentStep, maze.startPosition, cameFrom)
 			slices.Reverse(reconstructedPath)
-			return reconstructedPath, nil
+			return reconstructedPath, statistics, nil
 		}
 
 		maze.expandStepSingleOptimalPath(currentStep, openset, cameFrom)
 	}
 
-	return nil, errors.New("could not find path to end")
+	return nil, statistics, ErrorNoPathFound
 }
@@ -0,0 +1,376 @@
+package maze
+
+import (
+	"errors"
+	"hmcalister/AdventOfCode/gridutils"
+	"log/slog"
+	"math"
+	"slices"
+
+	priorityqueue "github.com/hmcalister/Go-DSA/queue/PriorityQueue"
+)
+
+//go:generate stringer --type SearchStrategy
+type SearchStrategy int
+
+const (
+	// A* search from the start to the end, using the Manhattan distance heuristic
+	SEARCH_STRATEGY_ASTAR SearchStrategy = 0
+
+	// A* search from both the start and the end simultaneously, stopping once the searches meet on an optimal path
+	SEARCH_STRATEGY_BIDIRECTIONAL_ASTAR SearchStrategy = 1
+
+	// Jump Point Search, pruning symmetric paths on uniform-cost 4-connected grids.
+	// Only valid for mazes with no weighted or one-way terrain.
+	SEARCH_STRATEGY_JUMP_POINT SearchStrategy = 2
+)
+
+var (
+	ErrorNoPathFound              error = errors.New("could not find path to end")
+	ErrorUnknownSearchStrategy    error = errors.New("unknown search strategy")
+	ErrorJumpPointRequiresUniform error = errors.New("jump point search requires a maze with uniform cost terrain")
+	AllSearchStrategies                 = []SearchStrategy{SEARCH_STRATEGY_ASTAR, SEARCH_STRATEGY_BIDIRECTIONAL_ASTAR, SEARCH_STRATEGY_JUMP_POINT}
+	orthogonalDirections                = []gridutils.Direction{gridutils.DIRECTION_UP, gridutils.DIRECTION_RIGHT, gridutils.DIRECTION_DOWN, gridutils.DIRECTION_LEFT}
+)
+
+// Statistics gathered during a single search, for comparing search strategies.
+type SearchStatistics struct {
+	Strategy SearchStrategy
+
+	// The number of nodes removed from the openset(s) and expanded
+	NodesExpanded int
+}
+
+// Set the strategy used by ComputeOptimalPath.
+func (maze *Maze) SetSearchStrategy(strategy SearchStrategy) {
+	maze.searchStrategy = strategy
+}
+
+// Find the optimal path using the search strategy of the maze,
+// and report statistics about the search.
+func (maze Maze) ComputeOptimalPathWithStatistics() ([]gridutils.Coordinate, SearchStatistics, error) {
+	switch maze.searchStrategy {
+	case SEARCH_STRATEGY_ASTAR:
+		return maze.computeOptimalPathAStar()
+	case SEARCH_STRATEGY_BIDIRECTIONAL_ASTAR:
+		return maze.computeOptimalPathBidirectionalAStar()
+	case SEARCH_STRATEGY_JUMP_POINT:
+		return maze.computeOptimalPathJumpPoint()
+	default:
+		return nil, SearchStatistics{Strategy: maze.searchStrategy}, ErrorUnknownSearchStrategy
+	}
+}
+
+// --------------------------------------------------------------------------------
+// Shared search helpers
+
+// An item in a search openset. Items are never updated in place;
+// a better path to a position is added as a new item, and stale items are skipped when removed.
+type searchItem struct {
+	position gridutils.Coordinate
+	gScore   int
+	fScore   int
+}
+
+func newSearchOpenset() *priorityqueue.PriorityQueue[searchItem] {
+	return priorityqueue.New(func(a, b searchItem) int {
+		if a.fScore != b.fScore {
+			return a.fScore - b.fScore
+		}
+		// Break ties in favor of deeper nodes, which are closer to the goal
+		return b.gScore - a.gScore
+	})
+}
+
+func manhattanDistance(a, b gridutils.Coordinate) int {
+	deltaX := a.X - b.X
+	if deltaX < 0 {
+		deltaX *= -1
+	}
+
+	deltaY := a.Y - b.Y
+	if deltaY < 0 {
+		deltaY *= -1
+	}
+
+	return deltaX + deltaY
+}
+
+func reverseDirection(d gridutils.Direction) gridutils.Direction {
+	return d.RotateLeft().RotateLeft()
+}
+
+// Walk the cameFrom map backwards from the final step to the initial step,
+// returning the steps in the order they were walked.
+func walkCameFrom(finalStep, initialStep gridutils.Coordinate, cameFrom map[gridutils.Coordinate]gridutils.Coordinate) []gridutils.Coordinate {
+	walkedPath := make([]gridutils.Coordinate, 0)
+	walkedStep := finalStep
+	for walkedStep != initialStep {
+		walkedPath = append(walkedPath, walkedStep)
+		walkedStep = cameFrom[walkedStep]
+	}
+	walkedPath = append(walkedPath, initialStep)
+	return walkedPath
+}
+
+// --------------------------------------------------------------------------------
+// Bidirectional A*
+
+// Expand a single node of one half of a bidirectional search.
+//
+// If reversed, steps are walked backwards (from the neighbor into the current step), so the search
+// respects one-way terrain when searching from the end. Returns the best meeting point found while expanding.
+func (maze Maze) expandBidirectionalStep(
+	currentItem searchItem,
+	reversed bool,
+	heuristicTarget gridutils.Coordinate,
+	openset *priorityqueue.PriorityQueue[searchItem],
+	gScore map[gridutils.Coordinate]int,
+	cameFrom map[gridutils.Coordinate]gridutils.Coordinate,
+	otherGScore map[gridutils.Coordinate]int,
+	bestMeetingCost int,
+	bestMeetingPoint gridutils.Coordinate,
+) (int, gridutils.Coordinate) {
+	for _, direction := range orthogonalDirections {
+		var nextStep gridutils.Coordinate
+		var stepCost int
+		var ok bool
+		if reversed {
+			nextStep = currentItem.position.Step(direction)
+			_, stepCost, ok = maze.tryStep(nextStep, reverseDirection(direction))
+		} else {
+			nextStep, stepCost, ok = maze.tryStep(currentItem.position, direction)
+		}
+		if !ok {
+			continue
+		}
+
+		nextGScore := currentItem.gScore + stepCost
+		if priorGScore, seen := gScore[nextStep]; seen && priorGScore <= nextGScore {
+			continue
+		}
+		gScore[nextStep] = nextGScore
+		cameFrom[nextStep] = currentItem.position
+		openset.Add(searchItem{
+			position: nextStep,
+			gScore:   nextGScore,
+			fScore:   nextGScore + maze.minimumEntryCost*manhattanDistance(nextStep, heuristicTarget),
+		})
+
+		if otherG, seen := otherGScore[nextStep]; seen && nextGScore+otherG < bestMeetingCost {
+			bestMeetingCost = nextGScore + otherG
+			bestMeetingPoint = nextStep
+		}
+	}
+	return bestMeetingCost, bestMeetingPoint
+}
+
+// Find the optimal path by running A* forwards from the start and backwards from the end,
+// alternating between the two searches. The search stops when neither openset can improve
+// on the best path found through a node seen by both searches.
+func (maze Maze) computeOptimalPathBidirectionalAStar() ([]gridutils.Coordinate, SearchStatistics, error) {
+	statistics := SearchStatistics{Strategy: SEARCH_STRATEGY_BIDIRECTIONAL_ASTAR}
+	if !maze.coordinateMap.Contains(maze.startPosition) || !maze.coordinateMap.Contains(maze.endPosition) {
+		return nil, statistics, ErrorNoPathFound
+	}
+
+	forwardOpenset := newSearchOpenset()
+	forwardGScore := map[gridutils.Coordinate]int{maze.startPosition: 0}
+	forwardCameFrom := make(map[gridutils.Coordinate]gridutils.Coordinate)
+	forwardOpenset.Add(searchItem{maze.startPosition, 0, maze.heuristic(maze.startPosition)})
+
+	backwardOpenset := newSearchOpenset()
+	backwardGScore := map[gridutils.Coordinate]int{maze.endPosition: 0}
+	backwardCameFrom := make(map[gridutils.Coordinate]gridutils.Coordinate)
+	backwardOpenset.Add(searchItem{maze.endPosition, 0, maze.minimumEntryCost * manhattanDistance(maze.endPosition, maze.startPosition)})
+
+	bestMeetingCost := math.MaxInt
+	bestMeetingPoint := maze.startPosition
+	if maze.startPosition == maze.endPosition {
+		bestMeetingCost = 0
+	}
+
+	expandForward := true
+	for forwardOpenset.Size() > 0 && backwardOpenset.Size() > 0 {
+		forwardTop, _ := forwardOpenset.Peek()
+		backwardTop, _ := backwardOpenset.Peek()
+		if forwardTop.fScore >= bestMeetingCost || backwardTop.fScore >= bestMeetingCost {
+			break
+		}
+
+		if expandForward {
+			currentItem, _ := forwardOpenset.Remove()
+			if currentItem.gScore == forwardGScore[currentItem.position] {
+				statistics.NodesExpanded += 1
+				bestMeetingCost, bestMeetingPoint = maze.expandBidirectionalStep(
+					currentItem, false, maze.endPosition,
+					forwardOpenset, forwardGScore, forwardCameFrom,
+					backwardGScore, bestMeetingCost, bestMeetingPoint,
+				)
+			}
+		} else {
+			currentItem, _ := backwardOpenset.Remove()
+			if currentItem.gScore == backwardGScore[currentItem.position] {
+				statistics.NodesExpanded += 1
+				bestMeetingCost, bestMeetingPoint = maze.expandBidirectionalStep(
+					currentItem, true, maze.startPosition,
+					backwardOpenset, backwardGScore, backwardCameFrom,
+					forwardGScore, bestMeetingCost, bestMeetingPoint,
+				)
+			}
+		}
+		expandForward = !expandForward
+	}
+
+	if bestMeetingCost == math.MaxInt {
+		return nil, statistics, ErrorNoPathFound
+	}
+	slog.Debug("bidirectional search met", "meeting point", bestMeetingPoint, "path cost", bestMeetingCost)
+
+	reconstructedPath := walkCameFrom(bestMeetingPoint, maze.startPosition, forwardCameFrom)
+	slices.Reverse(reconstructedPath)
+	backwardPath := walkCameFrom(bestMeetingPoint, maze.endPosition, backwardCameFrom)
+	reconstructedPath = append(reconstructedPath, backwardPath[1:]...)
+	return reconstructedPath, statistics, nil
+}
+
+// --------------------------------------------------------------------------------
+// Jump Point Search
+
+// Determine if every open cell of the maze has the same entry cost and no cell is one-way.
+func (maze Maze) hasUniformTerrain() bool {
+	for _, terrain := range maze.terrainMap {
+		if terrain.OneWay || terrain.EntryCost != emptyTerrain.EntryCost {
+			return false
+		}
+	}
+	return true
+}
+
+func isHorizontal(d gridutils.Direction) bool {
+	return d == gridutils.DIRECTION_LEFT || d == gridutils.DIRECTION_RIGHT
+}
+
+// Determine if a cell reached by moving horizontally has a forced vertical neighbor, that is,
+// an open vertical neighbor that could not have been reached by turning vertically one cell earlier.
+func (maze Maze) hasForcedNeighbor(position gridutils.Coordinate, direction gridutils.Direction) bool {
+	previousPosition := position.Step(reverseDirection(direction))
+	for _, verticalDirection := range []gridutils.Direction{gridutils.DIRECTION_UP, gridutils.DIRECTION_DOWN} {
+		if maze.coordinateMap.Contains(position.Step(verticalDirection)) &&
+			!maze.coordinateMap.Contains(previousPosition.Step(verticalDirection)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Jump from a position in a direction until a jump point is found.
+//
+// Horizontal jumps stop at the end or at cells with a forced neighbor.
+// Vertical jumps stop at the end or at cells from which a horizontal jump would find a jump point.
+// The boolean is false if the jump runs into a wall without finding a jump point.
+func (maze Maze) jump(position gridutils.Coordinate, direction gridutils.Direction) (gridutils.Coordinate, bool) {
+	for {
+		position = position.Step(direction)
+		if !maze.coordinateMap.Contains(position) {
+			return position, false
+		}
+		if position == maze.endPosition {
+			return position, true
+		}
+
+		if isHorizontal(direction) {
+			if maze.hasForcedNeighbor(position, direction) {
+				return position, true
+			}
+		} else {
+			for _, horizontalDirection := range []gridutils.Direction{gridutils.DIRECTION_LEFT, gridutils.DIRECTION_RIGHT} {
+				if _, ok := maze.jump(position, horizontalDirection); ok {
+					return position, true
+				}
+			}
+		}
+	}
+}
+
+// Get the directions to search from a jump point, given the direction the jump point was reached by.
+//
+// Vertical movement may continue or turn, horizontal movement may only continue or turn towards a forced neighbor.
+func (maze Maze) jumpPointSuccessorDirections(position gridutils.Coordinate, incomingDirection gridutils.Direction, isInitial bool) []gridutils.Direction {
+	if isInitial {
+		return orthogonalDirections
+	}
+	if !isHorizontal(incomingDirection) {
+		return []gridutils.Direction{incomingDirection, gridutils.DIRECTION_LEFT, gridutils.DIRECTION_RIGHT}
+	}
+
+	successorDirections := []gridutils.Direction{incomingDirection}
+	previousPosition := position.Step(reverseDirection(incomingDirection))
+	for _, verticalDirection := range []gridutils.Direction{gridutils.DIRECTION_UP, gridutils.DIRECTION_DOWN} {
+		if maze.coordinateMap.Contains(position.Step(verticalDirection)) &&
+			!maze.coordinateMap.Contains(previousPosition.Step(verticalDirection)) {
+			successorDirections = append(successorDirections, verticalDirection)
+		}
+	}
+	return successorDirections
+}
+
+// Find the optimal path by Jump Point Search, adapted for 4-connected grids.
+//
+// Vertical moves are taken as early as possible, so horizontal runs only branch where a wall forces them to.
+// Only jump points are expanded, which greatly reduces the work in large open areas.
+func (maze Maze) computeOptimalPathJumpPoint() ([]gridutils.Coordinate, SearchStatistics, error) {
+	statistics := SearchStatistics{Strategy: SEARCH_STRATEGY_JUMP_POINT}
+	if !maze.hasUniformTerrain() {
+		return nil, statistics, ErrorJumpPointRequiresUniform
+	}
+	if !maze.coordinateMap.Contains(maze.startPosition) || !maze.coordinateMap.Contains(maze.endPosition) {
+		return nil, statistics, ErrorNoPathFound
+	}
+
+	openset := newSearchOpenset()
+	gScore := map[gridutils.Coordinate]int{maze.startPosition: 0}
+	cameFrom := make(map[gridutils.Coordinate]gridutils.Coordinate)
+	incomingDirection := make(map[gridutils.Coordinate]gridutils.Direction)
+	openset.Add(searchItem{maze.startPosition, 0, maze.heuristic(maze.startPosition)})
+
+	for openset.Size() > 0 {
+		currentItem, _ := openset.Remove()
+		if currentItem.gScore != gScore[currentItem.position] {
+			continue
+		}
+		statistics.NodesExpanded += 1
+
+		if currentItem.position == maze.endPosition {
+			jumpPoints := walkCameFrom(currentItem.position, maze.startPosition, cameFrom)
+			slices.Reverse(jumpPoints)
+			reconstructedPath := []gridutils.Coordinate{maze.startPosition}
+			for _, jumpPoint := range jumpPoints[1:] {
+				previousPosition := reconstructedPath[len(reconstructedPath)-1]
+				for previousPosition != jumpPoint {
+					previousPosition = previousPosition.Step(incomingDirection[jumpPoint])
+					reconstructedPath = append(reconstructedPath, previousPosition)
+				}
+			}
+			return reconstructedPath, statistics, nil
+		}
+
+		isInitial := currentItem.position == maze.startPosition
+		for _, direction := range maze.jumpPointSuccessorDirections(currentItem.position, incomingDirection[currentItem.position], isInitial) {
+			jumpPoint, ok := maze.jump(currentItem.position, direction)
+			if !ok {
+				continue
+			}
+			jumpPointGScore := currentItem.gScore + emptyTerrain.EntryCost*manhattanDistance(currentItem.position, jumpPoint)
+			if priorGScore, seen := gScore[jumpPoint]; seen && priorGScore <= jumpPointGScore {
+				continue
+			}
+			gScore[jumpPoint] = jumpPointGScore
+			cameFrom[jumpPoint] = currentItem.position
+			incomingDirection[jumpPoint] = direction
+			openset.Add(searchItem{jumpPoint, jumpPointGScore, jumpPointGScore + maze.heuristic(jumpPoint)})
+		}
+	}
+
+	return nil, statistics, ErrorNoPathFound
+}
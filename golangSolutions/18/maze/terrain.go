@@ -0,0 +1,56 @@
+package maze
+
+import "hmcalister/AdventOfCode/gridutils"
+
+// A type of open cell in the maze.
+type Terrain struct {
+	// The rune used to represent this terrain in a maze string.
+	Rune rune
+
+	// The cost of moving into a cell of this terrain. Must be non-negative.
+	EntryCost int
+
+	// If true, a cell of this terrain can only be left by moving in ExitDirection.
+	OneWay        bool
+	ExitDirection gridutils.Direction
+}
+
+var (
+	emptyTerrain = Terrain{Rune: EMPTY_RUNE, EntryCost: 1}
+
+	// The terrain types understood by NewMazeWithTerrain.
+	DefaultTerrainTypes = []Terrain{
+		emptyTerrain,
+		{Rune: START_RUNE, EntryCost: 1},
+		{Rune: END_RUNE, EntryCost: 1},
+		{Rune: SWAMP_RUNE, EntryCost: 5},
+		{Rune: CONVEYOR_UP_RUNE, EntryCost: 1, OneWay: true, ExitDirection: gridutils.DIRECTION_UP},
+		{Rune: CONVEYOR_RIGHT_RUNE, EntryCost: 1, OneWay: true, ExitDirection: gridutils.DIRECTION_RIGHT},
+		{Rune: CONVEYOR_DOWN_RUNE, EntryCost: 1, OneWay: true, ExitDirection: gridutils.DIRECTION_DOWN},
+		{Rune: CONVEYOR_LEFT_RUNE, EntryCost: 1, OneWay: true, ExitDirection: gridutils.DIRECTION_LEFT},
+	}
+)
+
+// Get the terrain of an open cell. Cells without recorded terrain are empty.
+func (maze Maze) terrainAt(c gridutils.Coordinate) Terrain {
+	if terrain, ok := maze.terrainMap[c]; ok {
+		return terrain
+	}
+	return emptyTerrain
+}
+
+// Attempt to step from a cell in the given direction.
+//
+// Returns the coordinate stepped to and the cost of the step.
+// The boolean is false if the step is blocked, either by a wall or by a one-way cell.
+func (maze Maze) tryStep(from gridutils.Coordinate, direction gridutils.Direction) (gridutils.Coordinate, int, bool) {
+	to := from.Step(direction)
+	if !maze.coordinateMap.Contains(from) || !maze.coordinateMap.Contains(to) {
+		return to, 0, false
+	}
+	fromTerrain := maze.terrainAt(from)
+	if fromTerrain.OneWay && fromTerrain.ExitDirection != direction {
+		return to, 0, false
+	}
+	return to, maze.terrainAt(to).EntryCost, true
+}
@@ -4,13 +4,11 @@ import (
 	"bufio"
 	"flag"
 	"fmt"
-	"hmcalister/AdventOfCode/gridutils"
 	"hmcalister/AdventOfCode/maze"
 	"log/slog"
 	"os"
 	"runtime/pprof"
 	"slices"
-	"sync"
 	"time"
 )
 
@@ -18,11 +16,20 @@ const (
 	CPU_PROFILE_FILEPATH string = "profile"
 )
 
+var (
+	minCheatSaving          int
+	allowWallCheatEndpoints bool
+	numCheatsToPrint        int
+)
+
 func main() {
 	debugFlag := flag.Bool("debug", false, "Debug Flag")
 	inputFilePath := flag.String("inputFile", "puzzleInput", "Path to input file.")
 	selectedPart := flag.Int("part", 0, "Part to execute. Must be 1 or 2.")
 	profile := flag.Bool("profile", false, "Flag to profile program")
+	flag.IntVar(&minCheatSaving, "minCheatSaving", 100, "Minimum number of steps a cheat must save to be counted.")
+	flag.BoolVar(&allowWallCheatEndpoints, "allowWallCheatEndpoints", false, "Allow cheats to end inside a wall.")
+	flag.IntVar(&numCheatsToPrint, "printCheats", 0, "Number of the best cheats to print on the maze.")
 	flag.Parse()
 	if *profile {
 		f, err := os.Create(CPU_PROFILE_FILEPATH)
@@ -70,40 +77,8 @@ func main() {
 	slog.Info("computation completed", "result", result, "computation time elapsed (ns)", computationEndTime.Sub(computationStartTime).Nanoseconds())
 }
 
-func getAllCheatsUpToLength(initialPosition gridutils.Coordinate, maxCheatLength int) []gridutils.Coordinate {
-	allCheats := make([]gridutils.Coordinate, 0)
-	var remainingCheatLength int
-	for x := -maxCheatLength; x <= maxCheatLength; x += 1 {
-		if x >= 0 {
-			remainingCheatLength = maxCheatLength - x
-		} else {
-			remainingCheatLength = maxCheatLength + x
-		}
-		for y := -remainingCheatLength; y <= remainingCheatLength; y += 1 {
-			allCheats = append(allCheats, gridutils.Coordinate{
-				X: initialPosition.X + x,
-				Y: initialPosition.Y + y,
-			})
-		}
-	}
-
-	return allCheats
-}
-
-func getCheatLength(initialPosition, cheatPosition gridutils.Coordinate) int {
-	delX := initialPosition.X - cheatPosition.X
-	if delX < 0 {
-		delX *= -1
-	}
-	delY := initialPosition.Y - cheatPosition.Y
-	if delY < 0 {
-		delY *= -1
-	}
-
-	return delX + delY
-}
-
-func Part01(fileScanner *bufio.Scanner) (int, error) {
+// Find all cheats of at most maxCheatLength steps in the maze, and report how many save at least minCheatSaving steps.
+func countCheats(fileScanner *bufio.Scanner, maxCheatLength int) (int, error) {
 	mazeStrs := make([]string, 0)
 	for fileScanner.Scan() {
 		mazeStrs = append(mazeStrs, fileScanner.Text())
@@ -111,116 +86,38 @@ func Part01(fileScanner *bufio.Scanner) (int, error) {
 	mazeData := maze.NewMaze(mazeStrs)
 	fmt.Println(mazeData)
 
-	honestPath, err := mazeData.ComputeOptimalPath()
+	cheats, err := mazeData.FindCheats(maze.CheatQuery{
+		MaxLength:          maxCheatLength,
+		MinSaving:          minCheatSaving,
+		AllowWallEndpoints: allowWallCheatEndpoints,
+	})
 	if err != nil {
-		slog.Error("error when computing honest optimal path", "error", err)
+		slog.Error("error when finding cheats", "error", err)
 		return -1, err
 	}
 
-	cheatedPathSavingCounts := make(map[int]int)
-	for honestPathIndex, honestPathStep := range honestPath {
-		slog.Debug("walking path", "current path index", honestPathIndex+1, "path length", len(honestPath))
-		allPossibleCheats := getAllCheatsUpToLength(honestPathStep, 2)
-		for _, cheatStep := range allPossibleCheats {
-			cheatLength := getCheatLength(honestPathStep, cheatStep)
-			cheatStepIndex := slices.Index(honestPath, cheatStep)
-			if cheatStepIndex > honestPathIndex+cheatLength {
-				cheatSaving := cheatStepIndex - honestPathIndex - cheatLength
-				cheatedPathSavingCounts[cheatSaving] += 1
-			}
-		}
-	}
-
-	cheatLengths := make([]int, 0)
-	for cheatLength := range cheatedPathSavingCounts {
-		cheatLengths = append(cheatLengths, cheatLength)
-	}
-	slices.Sort(cheatLengths)
-
-	numCheatsAbove100 := 0
-	for _, cheatLength := range cheatLengths {
-		cheatCount := cheatedPathSavingCounts[cheatLength]
-		slog.Info("cheated path saving count", "cheated path saving", cheatLength, "number of cheats", cheatCount)
-		if cheatLength >= 100 {
-			numCheatsAbove100 += cheatCount
-		}
-	}
-
-	return numCheatsAbove100, nil
-}
-
-func Part02(fileScanner *bufio.Scanner) (int, error) {
-	mazeStrs := make([]string, 0)
-	for fileScanner.Scan() {
-		mazeStrs = append(mazeStrs, fileScanner.Text())
-	}
-	mazeData := maze.NewMaze(mazeStrs)
-	fmt.Println(mazeData)
-
-	honestPath, err := mazeData.ComputeOptimalPath()
-	if err != nil {
-		slog.Error("error when computing honest optimal path", "error", err)
-		return -1, err
+	cheatedPathSavingCounts := maze.CheatSavingHistogram(cheats)
+	cheatSavings := make([]int, 0)
+	for cheatSaving := range cheatedPathSavingCounts {
+		cheatSavings = append(cheatSavings, cheatSaving)
 	}
-
-	var workerWaitGroup sync.WaitGroup
-	pathIndexChannel := make(chan int)
-	resultsChannel := make(chan map[int]int)
-
-	for range 12 {
-		workerWaitGroup.Add(1)
-		go func(pathIndexChannel chan int, resultsChannel chan map[int]int) {
-			defer workerWaitGroup.Done()
-
-			workerCheatCounts := make(map[int]int)
-			for honestPathIndex := range pathIndexChannel {
-				honestPathStep := honestPath[honestPathIndex]
-				allPossibleCheats := getAllCheatsUpToLength(honestPathStep, 20)
-				for _, cheatStep := range allPossibleCheats {
-					cheatLength := getCheatLength(honestPathStep, cheatStep)
-					cheatStepIndex := slices.Index(honestPath, cheatStep)
-					if cheatStepIndex > honestPathIndex+cheatLength {
-						cheatSaving := cheatStepIndex - honestPathIndex - cheatLength
-						workerCheatCounts[cheatSaving] += 1
-					}
-				}
-			}
-			resultsChannel <- workerCheatCounts
-		}(pathIndexChannel, resultsChannel)
+	slices.Sort(cheatSavings)
+	for _, cheatSaving := range cheatSavings {
+		slog.Info("cheated path saving count", "cheated path saving", cheatSaving, "number of cheats", cheatedPathSavingCounts[cheatSaving])
 	}
 
-	for honestPathIndex := range honestPath {
-		slog.Debug("walking path", "current path index", honestPathIndex+1, "path length", len(honestPath))
-		pathIndexChannel <- honestPathIndex
+	for _, cheat := range cheats[:min(numCheatsToPrint, len(cheats))] {
+		fmt.Printf("cheat from %v to %v of length %v saves %v\n", cheat.Start, cheat.End, cheat.Length, cheat.Saving)
+		fmt.Println(mazeData.StringCheat(cheat))
 	}
 
-	close(pathIndexChannel)
-	go func() {
-		workerWaitGroup.Wait()
-		close(resultsChannel)
-	}()
-
-	cheatedPathSavingCounts := make(map[int]int)
-	for workerResult := range resultsChannel {
-		for k, v := range workerResult {
-			cheatedPathSavingCounts[k] += v
-		}
-	}
+	return len(cheats), nil
+}
 
-	cheatLengths := make([]int, 0)
-	for cheatLength := range cheatedPathSavingCounts {
-		cheatLengths = append(cheatLengths, cheatLength)
-	}
-	slices.Sort(cheatLengths)
-
-	numCheatsAbove100 := 0
-	for _, cheatLength := range cheatLengths {
-		cheatCount := cheatedPathSavingCounts[cheatLength]
-		slog.Info("cheated path saving count", "cheated path saving", cheatLength, "number of cheats", cheatCount)
-		if cheatLength >= 100 {
-			numCheatsAbove100 += cheatCount
-		}
-	}
+func Part01(fileScanner *bufio.Scanner) (int, error) {
+	return countCheats(fileScanner, 2)
+}
 
-	return numCheatsAbove100, nil
+func Part02(fileScanner *bufio.Scanner) (int, error) {
+	return countCheats(fileScanner, 20)
 }
@@ -0,0 +1,137 @@
+package maze
+
+import (
+	"errors"
+	"hmcalister/AdventOfCode/gridutils"
+	"slices"
+)
+
+// The rules used when searching a maze for cheats.
+type CheatQuery struct {
+	// The largest number of steps a single cheat may take.
+	MaxLength int
+
+	// The smallest saving (in steps, compared to the honest path) a cheat must make to be reported.
+	// Cheats that do not save any time are never reported.
+	MinSaving int
+
+	// If true, a cheat may end inside a wall, after which the racer must immediately
+	// step back onto the track. This step is counted in the path length but not the cheat length.
+	AllowWallEndpoints bool
+}
+
+// A single cheat, identified by the positions the cheat starts and ends on.
+type Cheat struct {
+	Start  gridutils.Coordinate
+	End    gridutils.Coordinate
+	Length int
+	Saving int
+}
+
+// Get every coordinate within maxCheatLength steps (in the Manhattan metric) of the initial position.
+func getAllCheatsUpToLength(initialPosition gridutils.Coordinate, maxCheatLength int) []gridutils.Coordinate {
+	allCheats := make([]gridutils.Coordinate, 0)
+	var remainingCheatLength int
+	for x := -maxCheatLength; x <= maxCheatLength; x += 1 {
+		if x >= 0 {
+			remainingCheatLength = maxCheatLength - x
+		} else {
+			remainingCheatLength = maxCheatLength + x
+		}
+		for y := -remainingCheatLength; y <= remainingCheatLength; y += 1 {
+			allCheats = append(allCheats, gridutils.Coordinate{
+				X: initialPosition.X + x,
+				Y: initialPosition.Y + y,
+			})
+		}
+	}
+
+	return allCheats
+}
+
+func getCheatLength(initialPosition, cheatPosition gridutils.Coordinate) int {
+	delX := initialPosition.X - cheatPosition.X
+	if delX < 0 {
+		delX *= -1
+	}
+	delY := initialPosition.Y - cheatPosition.Y
+	if delY < 0 {
+		delY *= -1
+	}
+
+	return delX + delY
+}
+
+// Get the distance from a cheat endpoint to the end of the maze.
+//
+// If wall endpoints are allowed and the endpoint is a wall, the racer steps out onto the best adjacent track cell.
+func (maze Maze) distanceFromCheatEndpoint(distancesToEnd DistanceGrid, cheatEnd gridutils.Coordinate, allowWallEndpoints bool) (int, bool) {
+	if distance, ok := distancesToEnd.Distance(cheatEnd); ok {
+		return distance, true
+	}
+	if !allowWallEndpoints || !distancesToEnd.inBounds(cheatEnd) || maze.coordinateMap.Contains(cheatEnd) {
+		return UNREACHABLE_DISTANCE, false
+	}
+
+	bestDistance := UNREACHABLE_DISTANCE
+	for _, neighbor := range cheatEnd.GetOrthogonalNeighbors() {
+		if distance, ok := distancesToEnd.Distance(neighbor); ok {
+			distance += maze.terrainAt(neighbor).EntryCost
+			if bestDistance == UNREACHABLE_DISTANCE || distance < bestDistance {
+				bestDistance = distance
+			}
+		}
+	}
+	return bestDistance, bestDistance != UNREACHABLE_DISTANCE
+}
+
+// Find every cheat matching the query.
+//
+// Cheats are scored using the distance from the start to the cheat start and the distance from the
+// cheat end to the end of the maze, so each cheat is an O(1) lookup and the maze need not be a single corridor.
+// Each step of a cheat costs one, regardless of the terrain passed through.
+//
+// Cheats are returned ordered by decreasing saving.
+func (maze Maze) FindCheats(query CheatQuery) ([]Cheat, error) {
+	distancesFromStart := maze.ComputeDistancesFromStart()
+	distancesToEnd := maze.ComputeDistancesToEnd()
+	honestPathLength, ok := distancesToEnd.Distance(maze.startPosition)
+	if !ok {
+		return nil, errors.New("could not find path to end")
+	}
+
+	cheats := make([]Cheat, 0)
+	for _, cheatStart := range distancesFromStart.ReachableCoordinates() {
+		distanceToCheatStart, _ := distancesFromStart.Distance(cheatStart)
+		for _, cheatEnd := range getAllCheatsUpToLength(cheatStart, query.MaxLength) {
+			distanceFromCheatEnd, ok := maze.distanceFromCheatEndpoint(distancesToEnd, cheatEnd, query.AllowWallEndpoints)
+			if !ok {
+				continue
+			}
+			cheatLength := getCheatLength(cheatStart, cheatEnd)
+			cheatSaving := honestPathLength - (distanceToCheatStart + cheatLength + distanceFromCheatEnd)
+			if cheatSaving > 0 && cheatSaving >= query.MinSaving {
+				cheats = append(cheats, Cheat{
+					Start:  cheatStart,
+					End:    cheatEnd,
+					Length: cheatLength,
+					Saving: cheatSaving,
+				})
+			}
+		}
+	}
+
+	slices.SortStableFunc(cheats, func(a, b Cheat) int {
+		return b.Saving - a.Saving
+	})
+	return cheats, nil
+}
+
+// Count the number of cheats by the time they save.
+func CheatSavingHistogram(cheats []Cheat) map[int]int {
+	cheatedPathSavingCounts := make(map[int]int)
+	for _, cheat := range cheats {
+		cheatedPathSavingCounts[cheat.Saving] += 1
+	}
+	return cheatedPathSavingCounts
+}
@@ -0,0 +1,121 @@
+package maze
+
+import (
+	"hmcalister/AdventOfCode/gridutils"
+
+	priorityqueue "github.com/hmcalister/Go-DSA/queue/PriorityQueue"
+)
+
+const (
+	UNREACHABLE_DISTANCE int = -1
+)
+
+// A dense grid of walking distances between some origin and every cell of a maze.
+//
+// Cells that cannot be reached from the origin (including walls and cells outside the maze)
+// are recorded as UNREACHABLE_DISTANCE.
+type DistanceGrid struct {
+	origin     gridutils.Coordinate
+	gridWidth  int
+	gridHeight int
+	distances  []int
+}
+
+func (grid DistanceGrid) inBounds(c gridutils.Coordinate) bool {
+	return 0 <= c.X && c.X < grid.gridWidth && 0 <= c.Y && c.Y < grid.gridHeight
+}
+
+// Get the walking distance between the grid origin and the given coordinate.
+//
+// The boolean is false if the coordinate is not reachable from the origin.
+func (grid DistanceGrid) Distance(c gridutils.Coordinate) (int, bool) {
+	if !grid.inBounds(c) {
+		return UNREACHABLE_DISTANCE, false
+	}
+	distance := grid.distances[grid.gridWidth*c.Y+c.X]
+	return distance, distance != UNREACHABLE_DISTANCE
+}
+
+// Get every coordinate reachable from the grid origin, in row-major order.
+func (grid DistanceGrid) ReachableCoordinates() []gridutils.Coordinate {
+	reachable := make([]gridutils.Coordinate, 0)
+	for linearIndex, distance := range grid.distances {
+		if distance != UNREACHABLE_DISTANCE {
+			reachable = append(reachable, gridutils.Coordinate{
+				X: linearIndex % grid.gridWidth,
+				Y: linearIndex / grid.gridWidth,
+			})
+		}
+	}
+	return reachable
+}
+
+type distanceSearchItem struct {
+	position gridutils.Coordinate
+	distance int
+}
+
+// Compute the walking distance between the origin and every other cell by Dijkstra's algorithm.
+//
+// If reversed is true, distances are computed from every cell to the origin, by walking steps backwards.
+func (maze Maze) computeDistanceGrid(origin gridutils.Coordinate, reversed bool) DistanceGrid {
+	grid := DistanceGrid{
+		origin:     origin,
+		gridWidth:  maze.mazeWidth,
+		gridHeight: maze.mazeHeight,
+		distances:  make([]int, maze.mazeWidth*maze.mazeHeight),
+	}
+	for linearIndex := range grid.distances {
+		grid.distances[linearIndex] = UNREACHABLE_DISTANCE
+	}
+	if !maze.coordinateMap.Contains(origin) {
+		return grid
+	}
+
+	searchQueue := priorityqueue.New(func(a, b distanceSearchItem) int {
+		return a.distance - b.distance
+	})
+	searchQueue.Add(distanceSearchItem{origin, 0})
+	for searchQueue.Size() > 0 {
+		currentItem, _ := searchQueue.Remove()
+		currentLinearIndex := grid.gridWidth*currentItem.position.Y + currentItem.position.X
+		if grid.distances[currentLinearIndex] != UNREACHABLE_DISTANCE {
+			continue
+		}
+		grid.distances[currentLinearIndex] = currentItem.distance
+
+		for _, direction := range gridutils.AllDirections {
+			var nextStep gridutils.Coordinate
+			var stepCost int
+			var ok bool
+			if reversed {
+				// Walking backwards, the step is taken from the neighbor into the current cell
+				nextStep = currentItem.position.Step(direction)
+				if !grid.inBounds(nextStep) {
+					continue
+				}
+				_, stepCost, ok = maze.tryStep(nextStep, direction.RotateLeft().RotateLeft())
+			} else {
+				nextStep, stepCost, ok = maze.tryStep(currentItem.position, direction)
+			}
+			if !ok || !grid.inBounds(nextStep) {
+				continue
+			}
+			if grid.distances[grid.gridWidth*nextStep.Y+nextStep.X] == UNREACHABLE_DISTANCE {
+				searchQueue.Add(distanceSearchItem{nextStep, currentItem.distance + stepCost})
+			}
+		}
+	}
+
+	return grid
+}
+
+// Compute the walking distance from the start position to every cell of the maze.
+func (maze Maze) ComputeDistancesFromStart() DistanceGrid {
+	return maze.computeDistanceGrid(maze.startPosition, false)
+}
+
+// Compute the walking distance from every cell of the maze to the end position.
+func (maze Maze) ComputeDistancesToEnd() DistanceGrid {
+	return maze.computeDistanceGrid(maze.endPosition, true)
+}
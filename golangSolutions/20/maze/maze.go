@@ -2,32 +2,56 @@ package maze
 
 import (
 	"errors"
+	"hmcalister/AdventOfCode/gridrender"
 	"hmcalister/AdventOfCode/gridutils"
 	"log/slog"
 	"math"
 	"slices"
+	"strconv"
 
 	priorityqueue "github.com/hmcalister/Go-DSA/queue/PriorityQueue"
 	hashset "github.com/hmcalister/Go-DSA/set/HashSet"
 )
 
 type Maze struct {
-	startPosition gridutils.Coordinate
-	endPosition   gridutils.Coordinate
-	coordinateMap *hashset.HashSet[gridutils.Coordinate]
-	mazeWidth     int
-	mazeHeight    int
-	gScore        map[gridutils.Coordinate]int
-	fScore        map[gridutils.Coordinate]int
+	startPosition    gridutils.Coordinate
+	endPosition      gridutils.Coordinate
+	coordinateMap    *hashset.HashSet[gridutils.Coordinate]
+	terrainMap       map[gridutils.Coordinate]Terrain
+	minimumEntryCost int
+	mazeWidth        int
+	mazeHeight       int
+	gScore           map[gridutils.Coordinate]int
+	fScore           map[gridutils.Coordinate]int
 }
 
+// Create a new maze using the default terrain types.
 func NewMaze(mazeStrings []string) Maze {
+	return NewMazeWithTerrain(mazeStrings, DefaultTerrainTypes)
+}
+
+// Create a new maze, parsing open cells using the given terrain types.
+//
+// The start and end runes mark the start and end positions, and take the terrain associated with those runes (if any).
+// Runes that are not walls and do not match any terrain type are treated as walls.
+func NewMazeWithTerrain(mazeStrings []string, terrainTypes []Terrain) Maze {
 	maze := Maze{
-		coordinateMap: hashset.New[gridutils.Coordinate](),
-		mazeWidth:     len(mazeStrings[0]),
-		mazeHeight:    len(mazeStrings),
-		gScore:        make(map[gridutils.Coordinate]int),
-		fScore:        make(map[gridutils.Coordinate]int),
+		coordinateMap:    hashset.New[gridutils.Coordinate](),
+		terrainMap:       make(map[gridutils.Coordinate]Terrain),
+		minimumEntryCost: math.MaxInt,
+		mazeWidth:        len(mazeStrings[0]),
+		mazeHeight:       len(mazeStrings),
+		gScore:           make(map[gridutils.Coordinate]int),
+		fScore:           make(map[gridutils.Coordinate]int),
+	}
+
+	terrainByRune := make(map[rune]Terrain)
+	for _, terrain := range terrainTypes {
+		if terrain.EntryCost < 0 {
+			slog.Error("terrain has negative entry cost, treating as zero", "terrain", terrain)
+			terrain.EntryCost = 0
+		}
+		terrainByRune[terrain.Rune] = terrain
 	}
 
 	for y, row := range mazeStrings {
@@ -36,77 +60,53 @@ func NewMaze(mazeStrings []string) Maze {
 			switch cell {
 			case START_RUNE:
 				maze.startPosition = c
-				maze.coordinateMap.Add(c)
 				slog.Debug("found start position", "coordinate", c)
 			case END_RUNE:
 				maze.endPosition = c
-				maze.coordinateMap.Add(c)
-				slog.Debug("found start position", "coordinate", c)
-			case EMPTY_RUNE:
-				maze.coordinateMap.Add(c)
+				slog.Debug("found end position", "coordinate", c)
 			case WALL_RUNE:
+				continue
+			}
 
-			default:
-				slog.Debug("found unexpected rune", "rune", cell, "coordinate", c)
+			terrain, ok := terrainByRune[cell]
+			if !ok {
+				if cell != START_RUNE && cell != END_RUNE {
+					slog.Debug("found unexpected rune", "rune", cell, "coordinate", c)
+					continue
+				}
+				terrain = emptyTerrain
 			}
+			maze.coordinateMap.Add(c)
+			if terrain != emptyTerrain {
+				maze.terrainMap[c] = terrain
+			}
+			maze.minimumEntryCost = min(maze.minimumEntryCost, terrain.EntryCost)
 		}
 	}
+	if maze.minimumEntryCost == math.MaxInt {
+		maze.minimumEntryCost = 0
+	}
 
 	return maze
 }
 
+func (maze Maze) StartPosition() gridutils.Coordinate {
+	return maze.startPosition
+}
+
+func (maze Maze) EndPosition() gridutils.Coordinate {
+	return maze.endPosition
+}
+
 // --------------------------------------------------------------------------------
 // Print methods
 
 func (maze Maze) String() string {
-	mazeString := make([]rune, maze.mazeHeight*(maze.mazeWidth+1))
-	mazeStringIndex := 0
-	for y := 0; y < maze.mazeHeight; y += 1 {
-		for x := 0; x < maze.mazeWidth; x += 1 {
-			c := gridutils.Coordinate{X: x, Y: y}
-			if c == maze.endPosition {
-				mazeString[mazeStringIndex] = END_RUNE
-			} else if c == maze.startPosition {
-				mazeString[mazeStringIndex] = START_RUNE
-			} else if !maze.coordinateMap.Contains(c) {
-				mazeString[mazeStringIndex] = WALL_RUNE
-			} else {
-				mazeString[mazeStringIndex] = '.'
-			}
-			mazeStringIndex += 1
-		}
-		mazeString[mazeStringIndex] = '\n'
-		mazeStringIndex += 1
-	}
-	return string(mazeString)
+	return gridrender.RenderASCII(maze.Canvas())
 }
 
 func (maze Maze) StringWithPath(path []gridutils.Coordinate) string {
-	mazeString := make([]rune, maze.mazeHeight*(maze.mazeWidth+1))
-	mazeStringIndex := 0
-	for y := 0; y < maze.mazeHeight; y += 1 {
-		for x := 0; x < maze.mazeWidth; x += 1 {
-			c := gridutils.Coordinate{X: x, Y: y}
-			if c == maze.endPosition {
-				mazeString[mazeStringIndex] = END_RUNE
-			} else if c == maze.startPosition {
-				mazeString[mazeStringIndex] = START_RUNE
-			} else if !maze.coordinateMap.Contains(c) {
-				mazeString[mazeStringIndex] = WALL_RUNE
-			} else {
-				mazeString[mazeStringIndex] = '.'
-			}
-			mazeStringIndex += 1
-		}
-		mazeString[mazeStringIndex] = '\n'
-		mazeStringIndex += 1
-	}
-	for _, pathStep := range path {
-		linearCoordinate := (maze.mazeWidth+1)*pathStep.Y + pathStep.X
-		mazeString[linearCoordinate] = 'O'
-	}
-
-	return string(mazeString)
+	return gridrender.RenderASCII(maze.Canvas(gridrender.PathOverlay{Path: path, Style: PathStyle}))
 }
 
 // --------------------------------------------------------------------------------
@@ -123,7 +123,7 @@ func (maze Maze) heuristic(step gridutils.Coordinate) int {
 		deltaY *= -1
 	}
 
-	return deltaX + deltaY
+	return maze.minimumEntryCost * (deltaX + deltaY)
 }
 
 func (maze Maze) getGScore(step gridutils.Coordinate) int {
@@ -148,9 +148,9 @@ func (maze Maze) expandStepSingleOptimalPath(
 	stepGScore := maze.getGScore(step)
 
 	for _, direction := range []gridutils.Direction{gridutils.DIRECTION_UP, gridutils.DIRECTION_RIGHT, gridutils.DIRECTION_DOWN, gridutils.DIRECTION_LEFT} {
-		nextStep := step.Step(direction)
-		if maze.coordinateMap.Contains(nextStep) {
-			forwardGScoreViaCurrent := stepGScore + 1
+		nextStep, stepCost, ok := maze.tryStep(step, direction)
+		if ok {
+			forwardGScoreViaCurrent := stepGScore + stepCost
 			forwardGScorePrior := maze.getGScore(nextStep)
 			if forwardGScoreViaCurrent < forwardGScorePrior {
 				// slog.Debug("expanded better path to next neighbor", "current step", step, "next step", nextStep, "next g", forwardGScoreViaCurrent)
@@ -205,33 +205,40 @@ func (maze Maze) ComputeOptimalPath() ([]gridutils.Coordinate, error) {
 // --------------------------------------------------------------------------------
 // Problem specific pathfinding
 
-func (maze Maze) StringTwoStepCheat(cheatOrigin gridutils.Coordinate, cheatDirection gridutils.Direction) string {
-	mazeString := make([]rune, maze.mazeHeight*(maze.mazeWidth+1))
-	mazeStringIndex := 0
-	for y := 0; y < maze.mazeHeight; y += 1 {
-		for x := 0; x < maze.mazeWidth; x += 1 {
-			c := gridutils.Coordinate{X: x, Y: y}
-			if c == maze.endPosition {
-				mazeString[mazeStringIndex] = END_RUNE
-			} else if c == maze.startPosition {
-				mazeString[mazeStringIndex] = START_RUNE
-			} else if !maze.coordinateMap.Contains(c) {
-				mazeString[mazeStringIndex] = WALL_RUNE
-			} else {
-				mazeString[mazeStringIndex] = '.'
-			}
-			mazeStringIndex += 1
+// Print the maze with the steps of a cheat labelled in order.
+//
+// The cheat is drawn moving horizontally first, then vertically. Steps are labelled 1-9, then a-z,
+// wrapping back to 0 for longer cheats.
+func (maze Maze) StringCheat(cheat Cheat) string {
+	cheatOverlay := gridrender.EntityOverlay{}
+
+	cheatedStep := cheat.Start
+	horizontalDirection := gridutils.DIRECTION_RIGHT
+	if cheat.End.X < cheat.Start.X {
+		horizontalDirection = gridutils.DIRECTION_LEFT
+	}
+	verticalDirection := gridutils.DIRECTION_DOWN
+	if cheat.End.Y < cheat.Start.Y {
+		verticalDirection = gridutils.DIRECTION_UP
+	}
+	for cheatStepIndex := range cheat.Length {
+		if cheatedStep.X != cheat.End.X {
+			cheatedStep = cheatedStep.Step(horizontalDirection)
+		} else {
+			cheatedStep = cheatedStep.Step(verticalDirection)
 		}
-		mazeString[mazeStringIndex] = '\n'
-		mazeStringIndex += 1
+		stepStyle := PathStyle
+		stepStyle.Rune = rune(strconv.FormatInt(int64((cheatStepIndex+1)%36), 36)[0])
+		cheatOverlay.Add(cheatedStep, stepStyle)
 	}
 
-	cheatedStep := cheatOrigin
-	for cheatStepIndex := range 2 {
-		cheatedStep = cheatedStep.Step(cheatDirection)
-		linearCoordinate := (maze.mazeWidth+1)*cheatedStep.Y + cheatedStep.X
-		mazeString[linearCoordinate] = rune(cheatStepIndex + 1 + '0')
-	}
+	return gridrender.RenderASCII(maze.Canvas(cheatOverlay))
+}
 
-	return string(mazeString)
+func (maze Maze) StringTwoStepCheat(cheatOrigin gridutils.Coordinate, cheatDirection gridutils.Direction) string {
+	return maze.StringCheat(Cheat{
+		Start:  cheatOrigin,
+		End:    cheatOrigin.Step(cheatDirection).Step(cheatDirection),
+		Length: 2,
+	})
 }
@@ -0,0 +1,5 @@
+module hmcalister/AdventOfCode
+
+go 1.23.0
+
+require github.com/hmcalister/Go-DSA v1.2.0
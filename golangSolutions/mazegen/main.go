@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+	"hmcalister/AdventOfCode/mazegen"
+	"log/slog"
+	"os"
+	"time"
+)
+
+const (
+	STYLE_PERFECT   string = "perfect"
+	STYLE_BRAIDED   string = "braided"
+	STYLE_RACETRACK string = "racetrack"
+	STYLE_BYTES     string = "bytes"
+)
+
+func main() {
+	debugFlag := flag.Bool("debug", false, "Debug Flag")
+	outputFilePath := flag.String("outputFile", "", "Path to output file. Prints to stdout if empty.")
+	style := flag.String("style", STYLE_PERFECT, "Style of input to generate. Must be one of perfect, braided (day 16), racetrack (day 20), or bytes (day 18).")
+	mazeWidth := flag.Int("width", 141, "Width of the generated maze. Mazes (other than bytes) are rounded down to an odd width.")
+	mazeHeight := flag.Int("height", 141, "Height of the generated maze. Mazes (other than bytes) are rounded down to an odd height.")
+	braidFraction := flag.Float64("braid", 0.5, "Fraction of dead ends to remove from braided mazes.")
+	numBytes := flag.Int("numBytes", 3450, "Number of falling bytes to generate.")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "Seed for the random generator. Defaults to the current time.")
+	flag.Parse()
+
+	logFileHandler := SetLogging(*debugFlag)
+	defer logFileHandler.Close()
+	slog.Info("generating input", "style", *style, "width", *mazeWidth, "height", *mazeHeight, "seed", *seed)
+
+	generator := mazegen.NewGenerator(*seed)
+	var generatedInput string
+	var generatedMaze *mazegen.Maze
+	var err error
+	switch *style {
+	case STYLE_PERFECT:
+		generatedMaze, err = generator.PerfectMaze(*mazeWidth, *mazeHeight)
+	case STYLE_BRAIDED:
+		generatedMaze, err = generator.BraidedMaze(*mazeWidth, *mazeHeight, *braidFraction)
+	case STYLE_RACETRACK:
+		generatedMaze, err = generator.Racetrack(*mazeWidth, *mazeHeight)
+	case STYLE_BYTES:
+		fallingByteCoords := generator.FallingBytes(*mazeWidth, *mazeHeight, *numBytes)
+		generatedInput = mazegen.FormatFallingBytes(*mazeWidth, *mazeHeight, fallingByteCoords)
+	default:
+		slog.Error("invalid style selected", "style", *style)
+		os.Exit(1)
+	}
+	if err != nil {
+		slog.Error("error encountered during generation", "error", err, "style", *style)
+		os.Exit(1)
+	}
+	if generatedMaze != nil {
+		generatedInput = generatedMaze.String()
+	}
+
+	outputFile := os.Stdout
+	if *outputFilePath != "" {
+		outputFile, err = os.Create(*outputFilePath)
+		if err != nil {
+			slog.Error("error creating output file", "error", err)
+			os.Exit(1)
+		}
+		defer outputFile.Close()
+	}
+	if _, err := outputFile.WriteString(generatedInput); err != nil {
+		slog.Error("error writing generated input", "error", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,283 @@
+package mazegen
+
+import (
+	"errors"
+	"fmt"
+	"hmcalister/AdventOfCode/gridutils"
+	"log/slog"
+	"math/rand"
+	"strings"
+
+	arrayqueue "github.com/hmcalister/Go-DSA/queue/ArrayQueue"
+	arraystack "github.com/hmcalister/Go-DSA/stack/ArrayStack"
+)
+
+const (
+	WALL_RUNE  rune = '#'
+	START_RUNE rune = 'S'
+	END_RUNE   rune = 'E'
+	EMPTY_RUNE rune = '.'
+)
+
+var (
+	ErrorMazeTooSmall error = errors.New("maze dimensions must be at least 5x5")
+)
+
+// A generated maze, stored as rows of runes in the same format as the puzzle input of day 16 and day 20.
+type Maze struct {
+	StartPosition gridutils.Coordinate
+	EndPosition   gridutils.Coordinate
+	grid          [][]rune
+	mazeWidth     int
+	mazeHeight    int
+}
+
+func newWallMaze(mazeWidth, mazeHeight int) *Maze {
+	grid := make([][]rune, mazeHeight)
+	for y := range grid {
+		grid[y] = make([]rune, mazeWidth)
+		for x := range grid[y] {
+			grid[y][x] = WALL_RUNE
+		}
+	}
+	return &Maze{
+		grid:       grid,
+		mazeWidth:  mazeWidth,
+		mazeHeight: mazeHeight,
+	}
+}
+
+func (maze *Maze) inBounds(c gridutils.Coordinate) bool {
+	return 0 <= c.X && c.X < maze.mazeWidth && 0 <= c.Y && c.Y < maze.mazeHeight
+}
+
+func (maze *Maze) isOpen(c gridutils.Coordinate) bool {
+	return maze.inBounds(c) && maze.grid[c.Y][c.X] != WALL_RUNE
+}
+
+func (maze *Maze) setRune(c gridutils.Coordinate, r rune) {
+	maze.grid[c.Y][c.X] = r
+}
+
+func (maze *Maze) placeStartAndEnd(startPosition, endPosition gridutils.Coordinate) {
+	maze.StartPosition = startPosition
+	maze.EndPosition = endPosition
+	maze.setRune(startPosition, START_RUNE)
+	maze.setRune(endPosition, END_RUNE)
+}
+
+// Get the maze as rows of strings, suitable for passing directly to maze.NewMaze.
+func (maze *Maze) Rows() []string {
+	rows := make([]string, maze.mazeHeight)
+	for y, row := range maze.grid {
+		rows[y] = string(row)
+	}
+	return rows
+}
+
+func (maze *Maze) String() string {
+	return strings.Join(maze.Rows(), "\n") + "\n"
+}
+
+// --------------------------------------------------------------------------------
+// Generator
+
+// Generates mazes from a seeded source of randomness, so any maze can be reproduced from its seed.
+type Generator struct {
+	random *rand.Rand
+}
+
+func NewGenerator(seed int64) *Generator {
+	return &Generator{
+		random: rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Round a dimension down to the nearest odd number, so the maze cells (at odd coordinates) are surrounded by walls.
+func oddDimension(dimension int) int {
+	if dimension%2 == 0 {
+		return dimension - 1
+	}
+	return dimension
+}
+
+// Carve a perfect maze (exactly one path between any two cells) by randomized depth first search.
+//
+// Cells lie on odd coordinates and the walls between them on even coordinates,
+// so the given dimensions are rounded down to odd numbers.
+func (generator *Generator) carvePerfectMaze(mazeWidth, mazeHeight int) (*Maze, error) {
+	if mazeWidth < 5 || mazeHeight < 5 {
+		return nil, ErrorMazeTooSmall
+	}
+	maze := newWallMaze(oddDimension(mazeWidth), oddDimension(mazeHeight))
+
+	initialCell := gridutils.Coordinate{X: 1, Y: 1}
+	maze.setRune(initialCell, EMPTY_RUNE)
+	carveStack := arraystack.New[gridutils.Coordinate]()
+	carveStack.Add(initialCell)
+	for carveStack.Size() > 0 {
+		currentCell, _ := carveStack.Peek()
+
+		unvisitedDirections := make([]gridutils.Direction, 0, 4)
+		for _, direction := range gridutils.AllDirections {
+			nextCell := currentCell.Step(direction).Step(direction)
+			if maze.inBounds(nextCell) && nextCell.X > 0 && nextCell.Y > 0 &&
+				nextCell.X < maze.mazeWidth-1 && nextCell.Y < maze.mazeHeight-1 &&
+				!maze.isOpen(nextCell) {
+				unvisitedDirections = append(unvisitedDirections, direction)
+			}
+		}
+		if len(unvisitedDirections) == 0 {
+			carveStack.Remove()
+			continue
+		}
+
+		direction := unvisitedDirections[generator.random.Intn(len(unvisitedDirections))]
+		maze.setRune(currentCell.Step(direction), EMPTY_RUNE)
+		nextCell := currentCell.Step(direction).Step(direction)
+		maze.setRune(nextCell, EMPTY_RUNE)
+		carveStack.Add(nextCell)
+	}
+
+	return maze, nil
+}
+
+// Generate a perfect maze in the style of day 16, starting in the bottom left and ending in the top right.
+func (generator *Generator) PerfectMaze(mazeWidth, mazeHeight int) (*Maze, error) {
+	maze, err := generator.carvePerfectMaze(mazeWidth, mazeHeight)
+	if err != nil {
+		return nil, err
+	}
+	maze.placeStartAndEnd(
+		gridutils.Coordinate{X: 1, Y: maze.mazeHeight - 2},
+		gridutils.Coordinate{X: maze.mazeWidth - 2, Y: 1},
+	)
+	slog.Debug("generated perfect maze", "width", maze.mazeWidth, "height", maze.mazeHeight)
+	return maze, nil
+}
+
+// Generate a braided maze in the style of day 16, starting in the bottom left and ending in the top right.
+//
+// A perfect maze is generated, then each dead end is removed with probability braidFraction by knocking
+// through one of its walls. This creates loops, and hence many paths of equal cost between the start and end.
+func (generator *Generator) BraidedMaze(mazeWidth, mazeHeight int, braidFraction float64) (*Maze, error) {
+	maze, err := generator.carvePerfectMaze(mazeWidth, mazeHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	for y := 1; y < maze.mazeHeight-1; y += 2 {
+		for x := 1; x < maze.mazeWidth-1; x += 2 {
+			cell := gridutils.Coordinate{X: x, Y: y}
+			openNeighbors := 0
+			knockableDirections := make([]gridutils.Direction, 0, 4)
+			for _, direction := range gridutils.AllDirections {
+				wall := cell.Step(direction)
+				if maze.isOpen(wall) {
+					openNeighbors += 1
+				} else if wall.X > 0 && wall.Y > 0 && wall.X < maze.mazeWidth-1 && wall.Y < maze.mazeHeight-1 {
+					knockableDirections = append(knockableDirections, direction)
+				}
+			}
+			if openNeighbors != 1 || len(knockableDirections) == 0 || generator.random.Float64() >= braidFraction {
+				continue
+			}
+			direction := knockableDirections[generator.random.Intn(len(knockableDirections))]
+			maze.setRune(cell.Step(direction), EMPTY_RUNE)
+		}
+	}
+
+	maze.placeStartAndEnd(
+		gridutils.Coordinate{X: 1, Y: maze.mazeHeight - 2},
+		gridutils.Coordinate{X: maze.mazeWidth - 2, Y: 1},
+	)
+	slog.Debug("generated braided maze", "width", maze.mazeWidth, "height", maze.mazeHeight, "braid fraction", braidFraction)
+	return maze, nil
+}
+
+// Find the distance from the origin to every open cell, along with the cell each was reached from.
+func (maze *Maze) breadthFirstSearch(origin gridutils.Coordinate) (map[gridutils.Coordinate]int, map[gridutils.Coordinate]gridutils.Coordinate) {
+	distances := map[gridutils.Coordinate]int{origin: 0}
+	cameFrom := make(map[gridutils.Coordinate]gridutils.Coordinate)
+	searchQueue := arrayqueue.New[gridutils.Coordinate]()
+	searchQueue.Add(origin)
+	for searchQueue.Size() > 0 {
+		currentStep, _ := searchQueue.Remove()
+		for _, nextStep := range currentStep.GetOrthogonalNeighbors() {
+			if _, seen := distances[nextStep]; seen || !maze.isOpen(nextStep) {
+				continue
+			}
+			distances[nextStep] = distances[currentStep] + 1
+			cameFrom[nextStep] = currentStep
+			searchQueue.Add(nextStep)
+		}
+	}
+	return distances, cameFrom
+}
+
+// Generate a racetrack in the style of day 20: a single corridor with no branches from the start to the end.
+//
+// A perfect maze is generated and a random cell chosen as the start. The end is the cell furthest from the start,
+// and every cell not on the path between the two is filled in.
+func (generator *Generator) Racetrack(mazeWidth, mazeHeight int) (*Maze, error) {
+	maze, err := generator.carvePerfectMaze(mazeWidth, mazeHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	startPosition := gridutils.Coordinate{
+		X: 2*generator.random.Intn((maze.mazeWidth-1)/2) + 1,
+		Y: 2*generator.random.Intn((maze.mazeHeight-1)/2) + 1,
+	}
+	distances, cameFrom := maze.breadthFirstSearch(startPosition)
+	endPosition := startPosition
+	for cell, distance := range distances {
+		// Break ties by coordinate, as map iteration order is random and would otherwise ruin reproducibility
+		if distance > distances[endPosition] ||
+			(distance == distances[endPosition] && (cell.Y < endPosition.Y || (cell.Y == endPosition.Y && cell.X < endPosition.X))) {
+			endPosition = cell
+		}
+	}
+
+	racetrack := newWallMaze(maze.mazeWidth, maze.mazeHeight)
+	for trackCell := endPosition; trackCell != startPosition; trackCell = cameFrom[trackCell] {
+		racetrack.setRune(trackCell, EMPTY_RUNE)
+	}
+	racetrack.placeStartAndEnd(startPosition, endPosition)
+	slog.Debug("generated racetrack", "width", racetrack.mazeWidth, "height", racetrack.mazeHeight, "track length", distances[endPosition])
+	return racetrack, nil
+}
+
+// --------------------------------------------------------------------------------
+// Falling bytes
+
+// Generate the coordinates of falling bytes in the style of day 18.
+//
+// Bytes never fall on the start (top left) or end (bottom right) of the memory space, and never fall on the same coordinate twice.
+// The number of bytes is capped at the number of remaining coordinates.
+func (generator *Generator) FallingBytes(mazeWidth, mazeHeight, numBytes int) []gridutils.Coordinate {
+	candidateCoordinates := make([]gridutils.Coordinate, 0, mazeWidth*mazeHeight)
+	for y := 0; y < mazeHeight; y += 1 {
+		for x := 0; x < mazeWidth; x += 1 {
+			c := gridutils.Coordinate{X: x, Y: y}
+			if (c == gridutils.Coordinate{X: 0, Y: 0}) || (c == gridutils.Coordinate{X: mazeWidth - 1, Y: mazeHeight - 1}) {
+				continue
+			}
+			candidateCoordinates = append(candidateCoordinates, c)
+		}
+	}
+	generator.random.Shuffle(len(candidateCoordinates), func(i, j int) {
+		candidateCoordinates[i], candidateCoordinates[j] = candidateCoordinates[j], candidateCoordinates[i]
+	})
+	return candidateCoordinates[:min(numBytes, len(candidateCoordinates))]
+}
+
+// Format falling bytes as day 18 input: the memory space dimensions, then one byte coordinate per line.
+func FormatFallingBytes(mazeWidth, mazeHeight int, fallingByteCoords []gridutils.Coordinate) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "%d,%d\n", mazeWidth, mazeHeight)
+	for _, fallingByteCoord := range fallingByteCoords {
+		fmt.Fprintf(&builder, "%d,%d\n", fallingByteCoord.X, fallingByteCoord.Y)
+	}
+	return builder.String()
+}